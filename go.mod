@@ -7,16 +7,16 @@ require (
 	github.com/0x5a17ed/uefi v0.6.0
 	github.com/Microsoft/go-winio v0.6.0
 	github.com/mattn/go-colorable v0.1.13
+	github.com/mattn/go-isatty v0.0.16
 	go.uber.org/multierr v1.8.0
+	golang.org/x/sys v0.1.0
 	golang.org/x/text v0.4.0
 )
 
 require (
-	github.com/mattn/go-isatty v0.0.16 // indirect
 	github.com/spf13/afero v1.9.2 // indirect
 	go.uber.org/atomic v1.10.0 // indirect
 	golang.org/x/exp v0.0.0-20221031165847-c99f073a8326 // indirect
 	golang.org/x/mod v0.6.0 // indirect
-	golang.org/x/sys v0.1.0 // indirect
 	golang.org/x/tools v0.2.0 // indirect
 )
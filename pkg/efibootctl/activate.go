@@ -0,0 +1,90 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+)
+
+// setBootEntryAttribute sets or clears the given attribute of the
+// boot entry with the given index, leaving everything else as it
+// is.
+func setBootEntryAttribute(c efivario.Context, index uint16, attr efitypes.Attributes, set bool) error {
+	return BootOptions.Update(c, index, func(lo *LoadOption) error {
+		if set {
+			lo.Attributes |= attr
+		} else {
+			lo.Attributes &^= attr
+		}
+		return nil
+	})
+}
+
+// Activate sets the active attribute of the boot entry with the
+// given index.
+func Activate(c efivario.Context, index uint16) error {
+	return setBootEntryAttribute(c, index, efitypes.ActiveAttribute, true)
+}
+
+// Deactivate clears the active attribute of the boot entry with
+// the given index.
+func Deactivate(c efivario.Context, index uint16) error {
+	return setBootEntryAttribute(c, index, efitypes.ActiveAttribute, false)
+}
+
+// activationE implements the commands applying fn to each of the
+// given boot entries, like activate and deactivate.
+func activationE(name string, fn func(c efivario.Context, index uint16) error, args []string) (err error) {
+	fs, out := newWriteFlagSet(name)
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%s: at least one boot entry index is required", name)
+	}
+
+	indices := make([]uint16, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		index, err := out.parseIndex(arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		indices = append(indices, index)
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	for _, index := range indices {
+		if err := fn(c, index); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func activateE(args []string) error {
+	return activationE("activate", Activate, args)
+}
+
+func deactivateE(args []string) error {
+	return activationE("deactivate", Deactivate, args)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+// annotatingContext is an efivario.Context wrapping the errors of
+// the wrapped context with the operation and the variable they
+// occurred on, e.g. "reading BootOrder: variable not found".  The
+// errors are wrapped with %w, so that errors.Is still matches
+// efivario.ErrNotFound and friends.
+type annotatingContext struct {
+	efivario.Context
+}
+
+var _ efivario.Context = &annotatingContext{}
+
+// newAnnotatingContext wraps c to annotate its errors.
+func newAnnotatingContext(c efivario.Context) efivario.Context {
+	return &annotatingContext{Context: c}
+}
+
+// variableLabel returns the name of the given variable as used in
+// error messages.  Variables outside of the global namespace are
+// qualified with their GUID.
+func variableLabel(name string, guid efiguid.GUID) string {
+	if guid == efivars.GlobalVariable {
+		return name
+	}
+	return dryRunKey(name, guid)
+}
+
+func (c *annotatingContext) GetSizeHint(name string, guid efiguid.GUID) (int64, error) {
+	n, err := c.Context.GetSizeHint(name, guid)
+	if err != nil {
+		return n, fmt.Errorf("reading %s: %w", variableLabel(name, guid), err)
+	}
+	return n, nil
+}
+
+func (c *annotatingContext) Get(name string, guid efiguid.GUID, out []byte) (efivario.Attributes, int, error) {
+	attrs, n, err := c.Context.Get(name, guid, out)
+	if err != nil {
+		return attrs, n, fmt.Errorf("reading %s: %w", variableLabel(name, guid), err)
+	}
+	return attrs, n, nil
+}
+
+func (c *annotatingContext) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	if err := c.Context.Set(name, guid, attrs, value); err != nil {
+		return fmt.Errorf("writing %s: %w", variableLabel(name, guid), err)
+	}
+	return nil
+}
+
+func (c *annotatingContext) Delete(name string, guid efiguid.GUID) error {
+	if err := c.Context.Delete(name, guid); err != nil {
+		return fmt.Errorf("deleting %s: %w", variableLabel(name, guid), err)
+	}
+	return nil
+}
+
+func (c *annotatingContext) VariableNames() (efivario.VariableNameIterator, error) {
+	it, err := c.Context.VariableNames()
+	if err != nil {
+		return nil, fmt.Errorf("listing variables: %w", err)
+	}
+	return it, nil
+}
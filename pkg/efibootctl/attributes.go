@@ -0,0 +1,124 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// attributeNames lists the known EFI variable attributes together
+// with their long and abbreviated names.
+var attributeNames = []struct {
+	attr  efivario.Attributes
+	name  string
+	short string
+}{
+	{efivario.NonVolatile, "NonVolatile", "NV"},
+	{efivario.BootServiceAccess, "BootServiceAccess", "BS"},
+	{efivario.RuntimeAccess, "RuntimeAccess", "RT"},
+	{efivario.HardwareErrorRecord, "HardwareErrorRecord", "HR"},
+	{efivario.AuthenticatedWriteAccess, "AuthenticatedWriteAccess", "AW"},
+	{efivario.TimeBasedAuthenticatedWriteAccess, "TimeBasedAuthenticatedWriteAccess", "AT"},
+	{efivario.AppendWrite, "AppendWrite", "AP"},
+	{efivario.EnhancedAuthenticatedAccess, "EnhancedAuthenticatedAccess", "EA"},
+}
+
+// formatAttributes returns the names of the given attributes
+// separated by "|".
+func formatAttributes(attrs efivario.Attributes) string {
+	var names []string
+	for _, an := range attributeNames {
+		if attrs&an.attr != 0 {
+			names = append(names, an.name)
+			attrs &^= an.attr
+		}
+	}
+	if attrs != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(attrs)))
+	}
+	return strings.Join(names, "|")
+}
+
+// AttributeFlags prints EFI variable attributes by their
+// abbreviated names, e.g. "NV,BS,RT".
+type AttributeFlags efivario.Attributes
+
+func (a AttributeFlags) String() string {
+	attrs := efivario.Attributes(a)
+
+	var names []string
+	for _, an := range attributeNames {
+		if attrs&an.attr != 0 {
+			names = append(names, an.short)
+			attrs &^= an.attr
+		}
+	}
+	if attrs != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(attrs)))
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements flag.Value and parses a comma separated list of
+// abbreviated or long attribute names, ignoring case.
+func (a *AttributeFlags) Set(s string) error {
+	var attrs efivario.Attributes
+	if s == "none" {
+		*a = 0
+		return nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		attr, ok := lookupAttribute(part)
+		if !ok {
+			return fmt.Errorf("unknown attribute %q", part)
+		}
+		attrs |= attr
+	}
+	*a = AttributeFlags(attrs)
+	return nil
+}
+
+// MarshalText renders the attributes in the same form as String.
+func (a AttributeFlags) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses attributes in the form accepted by Set.
+func (a *AttributeFlags) UnmarshalText(text []byte) error {
+	return a.Set(string(text))
+}
+
+func lookupAttribute(name string) (efivario.Attributes, bool) {
+	for _, an := range attributeNames {
+		if strings.EqualFold(name, an.short) || strings.EqualFold(name, an.name) {
+			return an.attr, true
+		}
+	}
+	return 0, false
+}
+
+func (a AttributeFlags) PrettyPrint(p *printer.Printer) {
+	p.ColorPrint(a.String(), printer.IntegerColor)
+	p.Print(fmt.Sprintf(" (%#x)", uint32(a)))
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+)
+
+// backupVersion is the version of the backup format written by
+// ExportBootVariables.
+const backupVersion = 1
+
+// backupSettings are the boot manager variables, apart from the
+// Boot#### entries, captured in a backup.
+var backupSettings = []string{efivars.BootOrderName, efivars.BootNextName, TimeoutName}
+
+// Backup holds the raw contents of the boot manager variables.
+type Backup struct {
+	Version int `json:"version"`
+
+	// BootCurrent is recorded for reference only and never
+	// restored since it is managed by the firmware.
+	BootCurrent *BootIndex `json:"bootCurrent,omitempty"`
+
+	Variables []BackupVariable `json:"variables"`
+}
+
+// BackupVariable holds the raw contents of a single variable in
+// the EFI global variable namespace.
+type BackupVariable struct {
+	Name       string         `json:"name"`
+	Index      *BootIndex     `json:"index,omitempty"`
+	Attributes AttributeFlags `json:"attributes"`
+	Data       []byte         `json:"data"`
+}
+
+// ExportBootVariables captures BootOrder, BootNext, Timeout and all
+// Boot#### variables currently present.  Missing variables are
+// left out.
+func ExportBootVariables(c efivario.Context) (*Backup, error) {
+	return exportBootVariables(c, nil)
+}
+
+// exportBootVariables implements ExportBootVariables.  report, if
+// not nil, is called with the number of Boot#### variables read so
+// far and their total.
+func exportBootVariables(c efivario.Context, report func(done, total int)) (*Backup, error) {
+	b := &Backup{Version: backupVersion, Variables: []BackupVariable{}}
+
+	_, bootCurrent, err := efivars.BootCurrent.Get(c)
+	switch {
+	case err == nil:
+		index := BootIndex(bootCurrent)
+		b.BootCurrent = &index
+	case !errors.Is(err, efivario.ErrNotFound):
+		return nil, err
+	}
+
+	read := func(name string) error {
+		attrs, data, err := readVariable(c, name, efivars.GlobalVariable)
+		if err != nil {
+			if errors.Is(err, efivario.ErrNotFound) {
+				return nil
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		v := BackupVariable{Name: name, Attributes: AttributeFlags(attrs), Data: data}
+		if index, ok := BootOptions.parseVariableName(name); ok {
+			bi := BootIndex(index)
+			v.Index = &bi
+		}
+		b.Variables = append(b.Variables, v)
+		return nil
+	}
+
+	for _, name := range backupSettings {
+		if err := read(name); err != nil {
+			return nil, err
+		}
+	}
+
+	indices, err := BootEntryIndices(c)
+	if err != nil {
+		return nil, err
+	}
+	for i, index := range indices {
+		if report != nil {
+			report(i, len(indices))
+		}
+		if err := read(bootEntryName(index)); err != nil {
+			return nil, err
+		}
+	}
+	if report != nil {
+		report(len(indices), len(indices))
+	}
+	return b, nil
+}
+
+// isBackupVariable reports whether the named variable may be
+// restored from a backup.
+func isBackupVariable(name string) bool {
+	if isBackupSetting(name) {
+		return true
+	}
+	_, ok := BootOptions.parseVariableName(name)
+	return ok
+}
+
+// isBackupSetting reports whether name is one of backupSettings.
+func isBackupSetting(name string) bool {
+	for _, setting := range backupSettings {
+		if name == setting {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportBootVariables writes the variables from the given backup.
+// Boot#### variables not part of the backup are left untouched.
+func ImportBootVariables(c efivario.Context, b *Backup) error {
+	if b.Version != backupVersion {
+		return fmt.Errorf("unsupported backup version %d", b.Version)
+	}
+	for _, v := range b.Variables {
+		if !isBackupVariable(v.Name) {
+			return fmt.Errorf("%s: not a boot manager variable", v.Name)
+		}
+	}
+
+	for _, v := range b.Variables {
+		err := SetVariable(c, v.Name, efivars.GlobalVariable, efivario.Attributes(v.Attributes), v.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportE(args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	outPath := fs.String("out", "-", "`path` to write the backup to, - for standard output")
+	quiet := fs.Bool("quiet", false, "do not show the progress on standard error")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := newAnnotatingContext(openVarStore())
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	pr := newProgress("Reading variables", *quiet)
+	b, err := exportBootVariables(c, pr.Update)
+	pr.Done()
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *outPath != "-" {
+		f, createErr := os.Create(*outPath)
+		if createErr != nil {
+			return fmt.Errorf("export: %w", createErr)
+		}
+		defer multierr.AppendInvoke(&err, multierr.Close(f))
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	return nil
+}
+
+// readBackupFile reads a backup written by the export command.
+func readBackupFile(path string) (*Backup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &b, nil
+}
+
+func importE(args []string) (err error) {
+	fs, out := newWriteFlagSet("import")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	force := fs.Bool("force", false, "actually write the variables")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("import: exactly one backup file is required")
+	}
+
+	b, err := readBackupFile(positional[0])
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	// Without --force the variables are only shown.
+	preview := !*force && !out.dryRun
+	if preview {
+		out.dryRun = true
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	if err := ImportBootVariables(c, b); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	if preview {
+		return errors.New("import: not written, pass --force to restore the variables")
+	}
+	return nil
+}
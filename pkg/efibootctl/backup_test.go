@@ -0,0 +1,83 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// truncatingStore is a MemoryVarStore whose Boot#### variables never
+// fit into the buffer they are read into.
+type truncatingStore struct {
+	*MemoryVarStore
+}
+
+func (s truncatingStore) Get(name string, guid efiguid.GUID, out []byte) (efivario.Attributes, int, error) {
+	attrs, n, err := s.MemoryVarStore.Get(name, guid, out)
+	if _, ok := BootOptions.parseVariableName(name); ok && err == nil {
+		return 0, 0, efivario.ErrInsufficientSpace
+	}
+	return attrs, n, err
+}
+
+func TestExportLargeBootEntry(t *testing.T) {
+	s := newTestStore(t, "a")
+	data := bytes.Repeat([]byte{0xa5}, 2500)
+	if err := SetOptionalData(s, 0, data); err != nil {
+		t.Fatal(err)
+	}
+	want, err := BootOptions.Read(s, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ExportBootVariables(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewMemoryVarStore()
+	if err := ImportBootVariables(restored, b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := BootOptions.Read(restored, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DescriptionString() != want.DescriptionString() || !bytes.Equal(got.OptionalData, want.OptionalData) {
+		t.Errorf("restored entry %q with %d bytes of optional data, want %q with %d bytes",
+			got.DescriptionString(), len(got.OptionalData), want.DescriptionString(), len(want.OptionalData))
+	}
+}
+
+func TestExportFailsOnUnreadableVariable(t *testing.T) {
+	useStore(t, truncatingStore{newTestStore(t, "a")})
+	path := filepath.Join(t.TempDir(), "backup.json")
+
+	err := exportE([]string{"--quiet", "--out", path})
+	if !errors.Is(err, efivario.ErrInsufficientSpace) {
+		t.Errorf("export = %v, want ErrInsufficientSpace", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("export left a backup behind: %v", err)
+	}
+}
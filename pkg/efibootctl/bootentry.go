@@ -0,0 +1,188 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+const (
+	// defaultAttributes are the EFI variable attributes used for
+	// all boot manager variables.
+	defaultAttributes = efivario.NonVolatile |
+		efivario.BootServiceAccess |
+		efivario.RuntimeAccess
+)
+
+var (
+	// ErrBootEntryNotFound is returned for missing Boot#### entries.
+	ErrBootEntryNotFound = ErrLoadOptionNotFound
+)
+
+// bootEntryName returns the variable name of the Boot#### entry
+// with the given index.
+func bootEntryName(index uint16) string {
+	return BootOptions.VariableName(index)
+}
+
+// ParseBootIndex parses a boot entry index in the same
+// hexadecimal form as it is printed, e.g. "0003", "3" or "0x0003".
+func ParseBootIndex(s string) (uint16, error) {
+	return ParseBootIndexBase(s, 16)
+}
+
+// ParseBootIndexBase parses a boot entry index in the given base.
+// Indices prefixed with "0x" are always read as hexadecimal.
+// Indices above 0xFFFF are rejected.
+func ParseBootIndexBase(s string, base int) (uint16, error) {
+	digits := s
+	if len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		digits, base = s[2:], 16
+	}
+	v, err := strconv.ParseUint(digits, base, 16)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) && errors.Is(numErr.Err, strconv.ErrRange) {
+			return 0, fmt.Errorf("boot entry index %q is above 0xFFFF", s)
+		}
+		return 0, fmt.Errorf("invalid boot entry index %q", s)
+	}
+	return uint16(v), nil
+}
+
+// BootEntryExists reports whether the Boot#### variable with the
+// given index exists.
+func BootEntryExists(c efivario.Context, index uint16) (bool, error) {
+	return BootOptions.Exists(c, index)
+}
+
+// BootEntryIndices returns the indices of all Boot#### variables
+// currently present.
+func BootEntryIndices(c efivario.Context) ([]uint16, error) {
+	return BootOptions.Indices(c)
+}
+
+// NextFreeBootIndex returns the lowest index not used by any
+// Boot#### variable.
+func NextFreeBootIndex(c efivario.Context) (uint16, error) {
+	return BootOptions.NextFreeIndex(c)
+}
+
+// GetBootOrder returns the current BootOrder.  A missing BootOrder
+// variable is reported as an empty order.
+func GetBootOrder(c efivario.Context) ([]uint16, error) {
+	return BootOptions.GetOrder(c)
+}
+
+// SetBootOrder replaces the BootOrder variable with the given order.
+func SetBootOrder(c efivario.Context, order []uint16) error {
+	return BootOptions.SetOrder(c, order)
+}
+
+// WriteBootEntry writes the given load option to the Boot####
+// variable with the given index.
+func WriteBootEntry(c efivario.Context, index uint16, lo *LoadOption) error {
+	return BootOptions.Write(c, index, lo)
+}
+
+// CreateBootEntry writes the given load option to the next free
+// Boot#### variable and appends it to the BootOrder.
+func CreateBootEntry(c efivario.Context, lo *LoadOption) (uint16, error) {
+	return BootOptions.Create(c, lo)
+}
+
+// DeleteBootEntries removes the Boot#### variables with the given
+// indices and drops them from the BootOrder.  The BootOrder is only
+// rewritten once all variables have been removed.
+func DeleteBootEntries(c efivario.Context, indices ...uint16) error {
+	return BootOptions.Delete(c, indices...)
+}
+
+// SetBootNext points BootNext at the Boot#### entry with the given
+// index, which must exist.
+func SetBootNext(c efivario.Context, index uint16) error {
+	if err := BootOptions.checkExists(c, index); err != nil {
+		return err
+	}
+	return efivars.BootNext.Set(c, index)
+}
+
+// ClearBootNext removes the BootNext variable.  It is not an error
+// if BootNext is not set.
+func ClearBootNext(c efivario.Context) error {
+	err := c.Delete(efivars.BootNextName, efivars.GlobalVariable)
+	if err != nil && !errors.Is(err, efivario.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ParseBootOrder parses a comma separated list of boot entry
+// indices and validates it against the given existing indices.
+// Every index has to refer to an existing entry and must only be
+// listed once.
+func ParseBootOrder(s string, existing []uint16) ([]uint16, error) {
+	return parseBootOrder(s, existing, 16)
+}
+
+// parseBootOrder implements ParseBootOrder for indices in the given
+// base.
+func parseBootOrder(s string, existing []uint16, base int) ([]uint16, error) {
+	known := make(map[uint16]bool, len(existing))
+	for _, index := range existing {
+		known[index] = true
+	}
+
+	parts := strings.Split(s, ",")
+	seen := make(map[uint16]bool, len(parts))
+	order := make([]uint16, 0, len(parts))
+	for _, part := range parts {
+		index, err := ParseBootIndexBase(strings.TrimSpace(part), base)
+		if err != nil {
+			return nil, err
+		}
+		if !known[index] {
+			return nil, fmt.Errorf("%s: %w", bootEntryName(index), ErrBootEntryNotFound)
+		}
+		if seen[index] {
+			return nil, fmt.Errorf("%s: listed more than once", bootEntryName(index))
+		}
+		seen[index] = true
+		order = append(order, index)
+	}
+	return order, nil
+}
+
+// MissingFromOrder returns the indices in existing which are not
+// part of the given order.
+func MissingFromOrder(order, existing []uint16) (out []uint16) {
+	listed := make(map[uint16]bool, len(order))
+	for _, index := range order {
+		listed[index] = true
+	}
+
+	for _, index := range existing {
+		if !listed[index] {
+			out = append(out, index)
+		}
+	}
+	return
+}
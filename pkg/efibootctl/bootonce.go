@@ -0,0 +1,86 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+)
+
+// CreateBootOnceEntry writes lo to the next free Boot#### variable
+// and points BootNext at it.  The entry is not added to the
+// BootOrder, so the firmware boots it exactly once.
+func CreateBootOnceEntry(c efivario.Context, lo *LoadOption) (uint16, error) {
+	index, err := NextFreeBootIndex(c)
+	if err != nil {
+		return 0, err
+	}
+	if err := WriteBootEntry(c, index, lo); err != nil {
+		return 0, err
+	}
+	if err := SetBootNext(c, index); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+func bootOnceE(args []string) (err error) {
+	fs, out := newWriteFlagSet("boot-once")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	lf := newLoaderFlags(fs)
+	reboot := fs.Bool("reboot", false, "reboot the system once BootNext is set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("boot-once: no arguments expected")
+	}
+
+	lo, err := lf.loadOption(fs)
+	if err != nil {
+		return fmt.Errorf("boot-once: %w", err)
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	index, err := CreateBootOnceEntry(c, lo)
+	if err != nil {
+		return fmt.Errorf("boot-once: %w", err)
+	}
+	warnBootNextSupport(c)
+
+	p := out.newPrinter()
+	p.PrintFieldValue(out.bootEntryName(index), lo.DescriptionString())
+	p.PrintFieldValue(efivars.BootNextName, out.bootIndex(index))
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	if !*reboot {
+		return nil
+	}
+	if out.dryRun {
+		_, _ = fmt.Fprintln(os.Stderr, "warning: not rebooting in dry run mode")
+		return nil
+	}
+	if err := rebootSystem(); err != nil {
+		return fmt.Errorf("boot-once: reboot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// BootOptionSupportName is the name of the variable describing the
+// boot manager features supported by the firmware.
+const BootOptionSupportName = "BootOptionSupport"
+
+// BootOptionSupportFlags is the bitmask held by the
+// BootOptionSupport variable.
+//
+// <https://uefi.org/specs/UEFI/2.10/03_Boot_Manager.html#boot-manager-capabilities>
+type BootOptionSupportFlags uint32
+
+const (
+	// BootOptionSupportKey means Key#### hot keys are supported.
+	BootOptionSupportKey BootOptionSupportFlags = 0x00000001
+
+	// BootOptionSupportApp means application load options are
+	// supported.
+	BootOptionSupportApp BootOptionSupportFlags = 0x00000002
+
+	// BootOptionSupportSysPrep means SysPrep#### load options are
+	// supported.
+	BootOptionSupportSysPrep BootOptionSupportFlags = 0x00000010
+
+	// BootOptionSupportCount masks the maximum number of keys
+	// which can be pressed together for a Key#### hot key.
+	BootOptionSupportCount BootOptionSupportFlags = 0x00000300
+)
+
+var bootOptionSupportNames = []struct {
+	bit  BootOptionSupportFlags
+	name string
+}{
+	{BootOptionSupportKey, "Key"},
+	{BootOptionSupportApp, "App"},
+	{BootOptionSupportSysPrep, "SysPrep"},
+}
+
+// KeyCount returns the maximum number of keys which can be pressed
+// together for a hot key.
+func (f BootOptionSupportFlags) KeyCount() int {
+	return int(f&BootOptionSupportCount) >> 8
+}
+
+// Names returns the names of the supported capabilities.  Unknown
+// bits are returned in hexadecimal.
+func (f BootOptionSupportFlags) Names() (names []string) {
+	rest := f &^ BootOptionSupportCount
+	for _, n := range bootOptionSupportNames {
+		if rest&n.bit != 0 {
+			names = append(names, n.name)
+			rest &^= n.bit
+		}
+	}
+	if f&BootOptionSupportKey != 0 {
+		names = append(names, fmt.Sprintf("KeyCount=%d", f.KeyCount()))
+	}
+	if rest != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(rest)))
+	}
+	return names
+}
+
+func (f BootOptionSupportFlags) String() string {
+	names := f.Names()
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// BootOptionSupport returns the boot manager capabilities advertised
+// by the firmware.  The returned bool is false if the firmware does
+// not expose the BootOptionSupport variable.
+func BootOptionSupport(c efivario.Context) (BootOptionSupportFlags, bool, error) {
+	data, err := readOptionalVariable(c, BootOptionSupportName)
+	if err != nil || data == nil {
+		return 0, false, err
+	}
+	if len(data) != 4 {
+		return 0, false, fmt.Errorf("%s: unexpected size %d", BootOptionSupportName, len(data))
+	}
+	return BootOptionSupportFlags(binary.LittleEndian.Uint32(data)), true, nil
+}
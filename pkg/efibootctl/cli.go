@@ -15,16 +15,18 @@
 package efibootctl
 
 import (
-	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/0x5a17ed/itkit/iters/sliceit"
-	"github.com/0x5a17ed/itkit/itlib"
-	"github.com/0x5a17ed/uefi/efi/efitypes"
 	"github.com/0x5a17ed/uefi/efi/efivario"
 	"github.com/0x5a17ed/uefi/efi/efivars"
-	"go.uber.org/multierr"
 
 	"github.com/0x5a17ed/efibootctl/pkg/printer"
 )
@@ -35,74 +37,390 @@ func (i BootIndex) PrettyPrint(p *printer.Printer) {
 	p.ColorPrint(fmt.Sprintf("%04X", i), printer.IntegerColor)
 }
 
-func mainE() (err error) {
-	c := efivario.NewDefaultContext()
-	defer multierr.AppendInvoke(&err, multierr.Close(c))
-
-	p := printer.NewPrinter("", printer.DefaultScheme, true, true, true)
+// MarshalText renders the index in the same hexadecimal form
+// as it is printed.
+func (i BootIndex) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%04X", uint16(i))), nil
+}
 
-	// Report BootNext value.
-	_, bootNext, err := efivars.BootNext.Get(c)
+// UnmarshalText parses an index in the form written by
+// MarshalText.
+func (i *BootIndex) UnmarshalText(text []byte) error {
+	v, err := ParseBootIndex(string(text))
 	if err != nil {
-		if !errors.Is(err, efivario.ErrNotFound) {
-			return err
+		return err
+	}
+	*i = BootIndex(v)
+	return nil
+}
+
+// decimalIndex is a boot entry index printed in decimal with
+// --decimal.  Unlike plain integers it is never grouped with
+// thousands separators, so that it can be passed back as is.
+type decimalIndex uint16
+
+func (i decimalIndex) PrettyPrint(p *printer.Printer) {
+	p.ColorPrint(strconv.FormatUint(uint64(i), 10), printer.IntegerColor)
+}
+
+// toDecimalIndices converts the given indices into decimalIndex
+// values for printing.  Like toBootIndices the result is never nil.
+func toDecimalIndices(indices []uint16) []decimalIndex {
+	out := make([]decimalIndex, 0, len(indices))
+	for _, v := range indices {
+		out = append(out, decimalIndex(v))
+	}
+	return out
+}
+
+// toBootIndices converts the given indices into BootIndex values
+// for printing.  The result is never nil, so that no indices are
+// encoded as an empty JSON array.
+func toBootIndices(indices []uint16) []BootIndex {
+	out := make([]BootIndex, 0, len(indices))
+	for _, v := range indices {
+		out = append(out, BootIndex(v))
+	}
+	return out
+}
+
+// parseInterspersed parses args with fs while allowing flags to
+// follow positional arguments, e.g. "clone 0001 --label x".  It
+// returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
 		}
-		// Ignore efivario.ErrNotFound errors.
-	} else {
-		p.PrintFieldValue("BootNext", BootIndex(bootNext))
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
 	}
+}
 
-	// Report BootCurrent value.
-	_, bootCurrent, err := efivars.BootCurrent.Get(c)
-	if err != nil {
+// isFlagSet reports whether the flag with the given name was
+// given on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) (found bool) {
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return
+}
+
+// outputFile implements the --output flag.  The file is only
+// created once the first output is written, so that a command
+// failing before it prints anything neither leaves an empty file
+// behind nor clobbers an existing one.
+type outputFile struct {
+	path string
+	f    *os.File
+	err  error
+}
+
+func (o *outputFile) String() string {
+	return o.path
+}
+
+// Set implements flag.Value.  The directory of the file is checked
+// right away, so that a mistyped path is still reported before any
+// variable is touched.
+func (o *outputFile) Set(s string) error {
+	if err := o.Close(); err != nil {
 		return err
 	}
-	p.PrintFieldValue("BootCurrent", BootIndex(bootCurrent))
 
-	// TODO: implement timeout value in uefi package.
+	o.path = s
+	if s == "-" {
+		return nil
+	}
 
-	_, bootOrder, err := efivars.BootOrder.Get(c)
+	dir := filepath.Dir(s)
+	fi, err := os.Stat(dir)
 	if err != nil {
 		return err
 	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s: not a directory", dir)
+	}
+	return nil
+}
 
-	p.PrintFieldValue("BootOrder", sliceit.To(itlib.Map(
-		sliceit.In(bootOrder), func(v uint16) BootIndex { return BootIndex(v) },
-	)))
+// isFile reports whether the output goes to a file.
+func (o *outputFile) isFile() bool {
+	return o.path != "" && o.path != "-"
+}
 
-	it, err := efivars.BootIterator(c)
-	if err != nil {
+// Write creates the file on the first call and writes p to it.  An
+// error creating the file is returned again by Close, since the
+// commands do not check the errors of their output writes.
+func (o *outputFile) Write(p []byte) (int, error) {
+	if o.f == nil && o.err == nil {
+		o.f, o.err = os.Create(o.path)
+	}
+	if o.err != nil {
+		return 0, o.err
+	}
+	return o.f.Write(p)
+}
+
+// Close closes the output file, if any.
+func (o *outputFile) Close() error {
+	if err := o.err; err != nil {
+		o.err = nil
 		return err
 	}
-	defer multierr.AppendInvoke(&err, multierr.Close(it))
+	if o.f == nil {
+		return nil
+	}
+	f := o.f
+	o.f = nil
+	return f.Close()
+}
 
-	itlib.Apply(it.Iter(), func(be *efivars.BootEntry) {
-		_, lo, err := be.Variable.Get(c)
-		if err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", err)
-			return
-		}
+// outputFlags holds the flags shared by all commands printing
+// to standard output.
+type outputFlags struct {
+	color   printer.ColorMode
+	verbose bool
+	debug   bool
+	dryRun  bool
+	plain   bool
+	decimal bool
+	output  outputFile
+
+	retries    int
+	retryDelay time.Duration
+}
+
+// indexBase returns the base boot entry indices are read and
+// printed in.
+func (o *outputFlags) indexBase() int {
+	if o.decimal {
+		return 10
+	}
+	return 16
+}
+
+// parseIndex parses a boot entry index given on the command line.
+func (o *outputFlags) parseIndex(s string) (uint16, error) {
+	return ParseBootIndexBase(s, o.indexBase())
+}
+
+// bootIndex returns the value to print for the given index.
+func (o *outputFlags) bootIndex(index uint16) any {
+	if o.decimal {
+		return decimalIndex(index)
+	}
+	return BootIndex(index)
+}
+
+// bootIndices returns the value to print for the given indices.
+func (o *outputFlags) bootIndices(indices []uint16) any {
+	if o.decimal {
+		return toDecimalIndices(indices)
+	}
+	return toBootIndices(indices)
+}
 
-		var isActive string
-		if lo.Attributes&efitypes.ActiveAttribute != 0 {
-			isActive = "*"
+// formatIndex returns the given index as text in the base indices
+// are read in, for output which is not rendered by the printer.
+func (o *outputFlags) formatIndex(index uint16) string {
+	if o.decimal {
+		return strconv.FormatUint(uint64(index), 10)
+	}
+	return fmt.Sprintf("%04X", index)
+}
+
+// bootEntryName returns the name under which the Boot#### entry
+// with the given index is printed.
+func (o *outputFlags) bootEntryName(index uint16) string {
+	if o.decimal {
+		return fmt.Sprintf("%s%d", BootOptions.Prefix, index)
+	}
+	return bootEntryName(index)
+}
+
+// writer returns the writer the output of the command goes to.
+// Escape sequences are stripped from the output written to a file
+// unless colors were requested explicitly.
+func (o *outputFlags) writer() io.Writer {
+	if o.output.isFile() {
+		if o.color == printer.ColorAlways {
+			return &o.output
 		}
+		return printer.NewStripWriter(&o.output)
+	}
+	return printer.DefaultOut
+}
 
-		p.PrintFieldValue(
-			fmt.Sprintf("Boot%04X%s", be.Index, isActive),
-			lo.DescriptionString(),
-		)
-		return
-	})
+// toTerminal reports whether the output goes to a terminal.
+func (o *outputFlags) toTerminal() bool {
+	return !o.output.isFile() && printer.IsTerminal(os.Stdout)
+}
 
-	_, _ = fmt.Fprint(printer.DefaultOut, p.String())
+// Close closes the file selected with --output, if any.
+func (o *outputFlags) Close() error {
+	return o.output.Close()
+}
 
+// debugFlag implements the -vv flag, which implies --verbose.
+type debugFlag struct{ o *outputFlags }
+
+func (f debugFlag) IsBoolFlag() bool { return true }
+
+func (f debugFlag) String() string {
+	if f.o == nil {
+		return "false"
+	}
+	return strconv.FormatBool(f.o.debug)
+}
+
+// Set implements flag.Value.
+func (f debugFlag) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	f.o.debug = v
+	if v {
+		f.o.verbose = true
+	}
 	return nil
 }
 
-func Run(binName string, args []string) {
-	if err := RunWithPrivileges(mainE); err != nil {
-		fmt.Printf("error: %s\n", err.Error())
-		os.Exit(1)
+// entryMarkers are appended to the name of a printed load option
+// depending on whether it is active.
+type entryMarkers struct {
+	Active   string
+	Inactive string
+}
+
+var (
+	// defaultMarkers mark active entries with an asterisk.
+	defaultMarkers = entryMarkers{Active: "*"}
+
+	// plainMarkers spell out the state of every entry, so that it
+	// can be picked up by screen readers and log parsers.
+	plainMarkers = entryMarkers{Active: " [active]", Inactive: " [inactive]"}
+)
+
+// newOutputFlagSet returns a new flag set for the given command
+// with the shared output flags registered.
+func newOutputFlagSet(name string) (*flag.FlagSet, *outputFlags) {
+	o := &outputFlags{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Var(&o.color, "color", "colorize the output: `when` is never, auto or always")
+	fs.BoolVar(&o.verbose, "verbose", false, "print more details and log every EFI variable write to standard error")
+	fs.BoolVar(&o.verbose, "v", false, "shorthand for --verbose")
+	fs.Var(debugFlag{o}, "vv", "like --verbose and also log every EFI variable read to standard error")
+	fs.BoolVar(&o.plain, "plain", false, "print without colors and with textual [active] and [inactive] markers")
+	fs.BoolVar(&o.decimal, "decimal", false, "read and print boot entry indices in decimal instead of hexadecimal")
+	fs.Var(&o.output, "output", "write the output to `file` instead of standard output, - for standard output")
+	return fs, o
+}
+
+// newWriteFlagSet returns a new flag set for the given command
+// modifying EFI variables with the shared output flags and the
+// --dry-run flag registered.
+func newWriteFlagSet(name string) (*flag.FlagSet, *outputFlags) {
+	fs, o := newOutputFlagSet(name)
+	fs.BoolVar(&o.dryRun, "dry-run", false, "print the variables which would be written instead of writing them")
+	fs.IntVar(&o.retries, "retries", defaultRetries, "retry writes failing with a transient error up to `n` times")
+	fs.DurationVar(&o.retryDelay, "retry-delay", defaultRetryDelay, "wait `duration` before the first retry, doubling it for every further retry")
+	return fs, o
+}
+
+// logLevel returns the variable accesses to log: writes with
+// --verbose and all accesses with -vv.
+func (o *outputFlags) logLevel() logLevel {
+	switch {
+	case o.debug:
+		return logAll
+	case o.verbose:
+		return logWrites
+	}
+	return logSilent
+}
+
+// openStore returns the VarStore to read from, logging the accesses
+// as selected by logLevel.  Errors are annotated with the variable
+// they occurred on.
+func (o *outputFlags) openStore() efivario.Context {
+	return newAnnotatingContext(newLoggingContext(openVarStore(), o.logLevel()))
+}
+
+// newContext returns the efivario.Context to operate on.  In dry
+// run mode writes are printed instead of being carried out.
+func (o *outputFlags) newContext() efivario.Context {
+	c := newRetryingContext(o.openStore(), o.retries, o.retryDelay)
+	if o.dryRun {
+		return newDryRunContext(c, o.newPrinter(), o.writer())
+	}
+	return c
+}
+
+// entryName returns the name under which the load option variable
+// with the given name is printed.  In verbose mode the name is
+// qualified with the GUID of its namespace.
+func (o *outputFlags) entryName(name string) string {
+	if o.verbose {
+		return name + "-" + strings.ToLower(efivars.GlobalVariable.String())
+	}
+	return name
+}
+
+// entryLabel returns the name of the given load option variable
+// marked with its active state.
+func (o *outputFlags) entryLabel(name string, active bool) string {
+	markers := defaultMarkers
+	if o.plain {
+		markers = plainMarkers
+	}
+
+	if active {
+		return o.entryName(name) + markers.Active
+	}
+	return o.entryName(name) + markers.Inactive
+}
+
+var (
+	colorSchemeOnce sync.Once
+	colorScheme     *printer.ColorScheme
+)
+
+// userColorScheme returns the default color scheme with the user's
+// overrides from the environment applied.
+func userColorScheme() *printer.ColorScheme {
+	colorSchemeOnce.Do(func() {
+		colorScheme = printer.SchemeFromEnv(printer.DefaultScheme)
+	})
+	return colorScheme
+}
+
+// newPrinter returns a printer honoring the color mode.
+func (o *outputFlags) newPrinter() *printer.Printer {
+	if o.plain {
+		p := printer.NewPrinter("", nil, true, true, true)
+		p.SetShowNotes(true)
+		p.SetLanguage(printer.LanguageFromEnv())
+		return p
+	}
+
+	// Colors are only useful on terminals unless requested
+	// explicitly.
+	mode := o.color
+	if o.output.isFile() && mode == printer.ColorAuto {
+		mode = printer.ColorNever
+	}
+	p := printer.NewPrinter("", mode.Scheme(userColorScheme()), true, true, true)
+	p.SetLanguage(printer.LanguageFromEnv())
+	if o.toTerminal() {
+		p.SetWidth(printer.TerminalWidth())
 	}
+	return p
 }
@@ -0,0 +1,78 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputFileIsCreatedOnFirstWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		args     []string
+		wantErr  bool
+		want     string
+		wantFile bool
+	}{
+		{name: "success", args: []string{"BootCurrent"}, want: "Variable:", wantFile: true},
+		{name: "argument error", args: nil, wantErr: true},
+		{name: "missing variable", args: []string{"Missing"}, wantErr: true},
+		{name: "existing file is kept", existing: "keep", args: []string{"Missing"}, wantErr: true, want: "keep", wantFile: true},
+		{name: "existing file is replaced", existing: "keep", args: []string{"BootCurrent"}, want: "Variable:", wantFile: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useStore(t, newTestStore(t, "a"))
+			path := filepath.Join(t.TempDir(), "output")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := getE(append([]string{"--output", path}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("get %v = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+
+			data, err := os.ReadFile(path)
+			switch {
+			case !tt.wantFile && !os.IsNotExist(err):
+				t.Errorf("output file exists: %v", err)
+			case tt.wantFile && err != nil:
+				t.Fatal(err)
+			case tt.wantFile && !strings.HasPrefix(string(data), tt.want):
+				t.Errorf("output = %q, want it to start with %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFileRejectsMissingDirectory(t *testing.T) {
+	useStore(t, newTestStore(t, "a"))
+	path := filepath.Join(t.TempDir(), "missing", "output")
+
+	var err error
+	captureStderr(t, func() {
+		err = getE([]string{"--output", path, "BootCurrent"})
+	})
+	if err == nil {
+		t.Error("get with an --output in a missing directory succeeded")
+	}
+}
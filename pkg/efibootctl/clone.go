@@ -0,0 +1,91 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+)
+
+// appendArgs appends the given arguments to the optional data.
+// Optional data holding utf16 text is extended with a separating
+// space, anything else has the utf16 encoded arguments appended.
+func appendArgs(data []byte, args string) []byte {
+	if text, ok := decodeTextData(data); ok {
+		return encodeUTF16(text + " " + args)
+	}
+	return append(append([]byte(nil), data...), encodeUTF16(args)...)
+}
+
+// CloneBootEntry copies the boot entry with the given index to the
+// next free index with a new description and appends it to the
+// BootOrder.  Non-empty args are appended to the optional data of
+// the copy.
+func CloneBootEntry(c efivario.Context, index uint16, desc, args string) (uint16, error) {
+	if desc == "" {
+		return 0, ErrEmptyDescription
+	}
+
+	lo, err := BootOptions.Read(c, index)
+	if err != nil {
+		return 0, err
+	}
+
+	lo.SetDescription(desc)
+	if args != "" {
+		lo.OptionalData = appendArgs(lo.OptionalData, args)
+	}
+	return BootOptions.Create(c, lo)
+}
+
+func cloneE(args []string) (err error) {
+	fs, out := newWriteFlagSet("clone")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	label := fs.String("label", "", "`description` of the new entry")
+	extraArgs := fs.String("append-args", "", "`text` to append to the optional data of the new entry")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(positional) != 1:
+		return errors.New("clone: exactly one boot entry index is required")
+	case *label == "":
+		return errors.New("clone: --label is required")
+	}
+
+	index, err := out.parseIndex(positional[0])
+	if err != nil {
+		return fmt.Errorf("clone: %w", err)
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	newIndex, err := CloneBootEntry(c, index, *label, *extraArgs)
+	if err != nil {
+		return fmt.Errorf("clone: %w", err)
+	}
+
+	p := out.newPrinter()
+	p.PrintFieldValue(bootEntryName(newIndex), *label)
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// command is a sub-command of efibootctl.
+type command struct {
+	// run executes the command with the arguments following its
+	// name.
+	run func(args []string) error
+
+	// summary describes the command in the usage message.
+	summary string
+
+	// unprivileged commands are run without checking for and
+	// acquiring access to the EFI variables.
+	unprivileged bool
+}
+
+// defaultCommand is run if no command name is given.
+const defaultCommand = "list"
+
+// commands maps sub-command names to their implementation.  Names
+// starting with "__" are hidden from the usage message.
+var commands = map[string]*command{
+	"list":               {run: listE, summary: "list the boot entries (default)"},
+	"create":             {run: createE, summary: "create a boot entry"},
+	"create-network":     {run: createNetworkE, summary: "create a PXE boot entry for a network interface"},
+	"boot-once":          {run: bootOnceE, summary: "create a boot entry and boot it once on the next reboot"},
+	"delete":             {run: deleteE, summary: "delete boot entries"},
+	"next":               {run: nextE, summary: "set or clear BootNext"},
+	"order":              {run: orderE, summary: "change the BootOrder"},
+	"get-order":          {run: getOrderE, summary: "print the BootOrder as a comma separated list"},
+	"move":               {run: moveE, summary: "move a boot entry within the BootOrder"},
+	"delete-next":        {run: deleteNextE, summary: "clear BootNext"},
+	"timeout":            {run: timeoutE, summary: "read, set or clear the boot manager timeout"},
+	"drivers":            {run: driversE, summary: "list the Driver#### entries"},
+	"sysprep":            {run: sysprepE, summary: "list, create or delete SysPrep#### entries"},
+	"activate":           {run: activateE, summary: "mark boot entries active"},
+	"deactivate":         {run: deactivateE, summary: "mark boot entries inactive"},
+	"hide":               {run: hideE, summary: "hide boot entries from the firmware boot menu"},
+	"unhide":             {run: unhideE, summary: "show hidden boot entries in the firmware boot menu again"},
+	"rename":             {run: renameE, summary: "change the description of a boot entry"},
+	"indications":        {run: indicationsE, summary: "list the OsIndications supported by the firmware"},
+	"firmware-info":      {run: firmwareInfoE, summary: "print the firmware vendor, revision and language"},
+	"reboot-to-firmware": {run: rebootToFirmwareE, summary: "boot into the firmware setup on the next reboot"},
+	"clone":              {run: cloneE, summary: "duplicate a boot entry under a new label"},
+	"set-args":           {run: setArgsE, summary: "replace the optional data of a boot entry"},
+	"get":                {run: getE, summary: "dump a raw EFI variable"},
+	"set":                {run: setE, summary: "write a raw EFI variable from a file"},
+	"enumerate":          {run: enumerateE, summary: "list the names of all EFI variables"},
+	"export":             {run: exportE, summary: "back up the boot manager variables to JSON"},
+	"import":             {run: importE, summary: "restore the boot manager variables from JSON"},
+	"diff":               {run: diffE, summary: "compare the boot manager variables with a JSON backup"},
+	"json-schema":        {run: jsonSchemaE, summary: "print the JSON Schema of the list --json output", unprivileged: true},
+	"secureboot":         {run: secureBootE, summary: "summarize the Secure Boot state and signature databases"},
+	"verify":             {run: verifyE, summary: "find dangling BootOrder and BootNext references"},
+	"dedupe":             {run: dedupeE, summary: "find and remove duplicate boot entries"},
+	"watch":              {run: watchE, summary: "redraw the listing whenever it changes"},
+	"completion":         {run: completionE, summary: "print a shell completion script", unprivileged: true},
+}
+
+func init() {
+	// Registered here as these commands refer to the commands map.
+	commands["help"] = &command{run: helpE, summary: "show the usage of efibootctl or a command", unprivileged: true}
+	commands[completeCommand] = &command{run: completeE, unprivileged: true}
+}
+
+// commandNames returns the sorted names of all commands which are
+// not hidden.
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isHelpFlag reports whether arg requests the usage message.
+func isHelpFlag(arg string) bool {
+	switch arg {
+	case "-h", "-help", "--help":
+		return true
+	}
+	return false
+}
+
+// lookupCommand returns the command selected by the first argument
+// together with the remaining arguments.  The boot entries are
+// listed if args does not start with a command name.
+func lookupCommand(args []string) (string, *command, []string, error) {
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !isHelpFlag(args[0])) {
+		return defaultCommand, commands[defaultCommand], args, nil
+	}
+
+	name := args[0]
+	if isHelpFlag(name) {
+		name = "help"
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		return "", nil, nil, fmt.Errorf("unknown command %q, see --help", name)
+	}
+	return name, cmd, args[1:], nil
+}
+
+// printUsage writes the usage message listing all commands to w.
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprint(w, "Usage: efibootctl [command] [flags] [arguments]\n\n")
+	_, _ = fmt.Fprint(w, "Manipulates the UEFI boot manager.\n\nCommands:\n")
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, name := range commandNames() {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\n", name, commands[name].summary)
+	}
+	_ = tw.Flush()
+
+	_, _ = fmt.Fprint(w, "\nRun \"efibootctl help <command>\" to show the flags of a command.\n\n")
+	_, _ = fmt.Fprint(w, exitCodesHelp)
+}
+
+func helpE(args []string) error {
+	switch len(args) {
+	case 0:
+		printUsage(os.Stdout)
+		return nil
+	case 1:
+		cmd, ok := commands[args[0]]
+		if !ok {
+			return fmt.Errorf("help: unknown command %q", args[0])
+		}
+		return cmd.run([]string{"--help"})
+	}
+	return errors.New("help: at most one command name is allowed")
+}
+
+func Run(binName string, args []string) {
+	_, cmd, args, err := lookupCommand(args)
+	if err != nil {
+		fmt.Printf("error: %s\n", err.Error())
+		os.Exit(ExitFailure)
+	}
+
+	fn := func() error { return cmd.run(args) }
+
+	if cmd.unprivileged {
+		err = fn()
+	} else if err = CheckEFISystem(); err == nil {
+		err = RunWithPrivileges(fn)
+	}
+
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			// The usage of the command was requested and has
+			// already been printed by its flag set.
+			_, _ = fmt.Fprint(os.Stderr, "\n"+exitCodesHelp)
+			os.Exit(ExitOK)
+		}
+		fmt.Printf("error: %s\n", err.Error())
+		os.Exit(ExitCode(err))
+	}
+}
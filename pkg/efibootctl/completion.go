@@ -0,0 +1,123 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"go.uber.org/multierr"
+)
+
+// completeCommand is the hidden command queried by the completion
+// scripts for candidates.
+const completeCommand = "__complete"
+
+// indexCommands are the commands taking boot entry indices as
+// positional arguments.
+var indexCommands = map[string]bool{
+	"delete":     true,
+	"activate":   true,
+	"deactivate": true,
+	"hide":       true,
+	"unhide":     true,
+	"next":       true,
+	"move":       true,
+	"rename":     true,
+	"clone":      true,
+	"set-args":   true,
+}
+
+const bashCompletion = `# bash completion for efibootctl
+_efibootctl() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	local IFS=$'\n'
+	COMPREPLY=($(compgen -W "$(efibootctl __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" 2>/dev/null)" -- "$cur"))
+}
+complete -F _efibootctl efibootctl
+`
+
+const zshCompletion = `#compdef efibootctl
+# zsh completion for efibootctl
+_efibootctl() {
+	local -a candidates
+	candidates=(${(f)"$(efibootctl __complete ${words[2,CURRENT-1]} 2>/dev/null)"})
+	compadd -a candidates
+}
+
+if [ "$funcstack[1]" = "_efibootctl" ]; then
+	_efibootctl "$@"
+else
+	compdef _efibootctl efibootctl
+fi
+`
+
+const fishCompletion = `# fish completion for efibootctl
+function __efibootctl_complete
+	set -l tokens (commandline -opc)
+	efibootctl __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c efibootctl -f -a '(__efibootctl_complete)'
+`
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+func completionE(args []string) error {
+	if len(args) != 1 {
+		return errors.New("completion: a shell is required: bash, zsh or fish")
+	}
+
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return fmt.Errorf("completion: unsupported shell %q", args[0])
+	}
+	_, _ = fmt.Fprint(os.Stdout, script)
+	return nil
+}
+
+// completeE prints the completion candidates for the word following
+// the given words, one per line.  Only variable names are listed,
+// so that completion works without elevated privileges.
+func completeE(args []string) (err error) {
+	if len(args) == 0 {
+		for _, name := range commandNames() {
+			_, _ = fmt.Fprintln(os.Stdout, name)
+		}
+		return nil
+	}
+
+	if !indexCommands[args[0]] {
+		return nil
+	}
+
+	c := openVarStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	indices, err := BootEntryIndices(c)
+	if err != nil {
+		return err
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	for _, index := range indices {
+		_, _ = fmt.Fprintf(os.Stdout, "%04X\n", index)
+	}
+	return nil
+}
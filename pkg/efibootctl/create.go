@@ -0,0 +1,125 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"go.uber.org/multierr"
+)
+
+func createE(args []string) error {
+	return createLoadOption("create", BootOptions, args)
+}
+
+// createLoadOption implements the create command for load options
+// of the given kind.
+func createLoadOption(name string, k *LoadOptionKind, args []string) (err error) {
+	fs, out := newWriteFlagSet(name)
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	lf := newLoaderFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	lo, err := lf.loadOption(fs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return out.createLoadOption(name, k, lo)
+}
+
+// loaderFlags are the flags describing a new load option pointing at
+// a loader on a disk partition.
+type loaderFlags struct {
+	disk     *string
+	part     *uint
+	esp      *string
+	loader   *string
+	label    *string
+	inactive *bool
+}
+
+// newLoaderFlags registers the flags describing a new load option
+// with fs.
+func newLoaderFlags(fs *flag.FlagSet) *loaderFlags {
+	return &loaderFlags{
+		disk:     fs.String("disk", "", "disk `device` containing the loader"),
+		part:     fs.Uint("part", 1, "partition `number` containing the loader"),
+		esp:      fs.String("esp", "", "mount `point` of the EFI system partition containing the loader, instead of --disk and --part"),
+		loader:   fs.String("loader", "", "`path` to the loader on the partition"),
+		label:    fs.String("label", "", "`description` of the new entry"),
+		inactive: fs.Bool("inactive", false, "create the entry without the active attribute"),
+	}
+}
+
+// loadOption validates the flags parsed by fs and returns the load
+// option they describe.
+func (f *loaderFlags) loadOption(fs *flag.FlagSet) (*LoadOption, error) {
+	switch {
+	case *f.esp != "" && (*f.disk != "" || isFlagSet(fs, "part")):
+		return nil, errors.New("--esp cannot be combined with --disk or --part")
+	case *f.esp == "" && *f.disk == "":
+		return nil, errors.New("--disk or --esp is required")
+	case *f.loader == "":
+		return nil, errors.New("--loader is required")
+	case *f.label == "":
+		return nil, errors.New("--label is required")
+	}
+
+	disk, number := *f.disk, uint32(*f.part)
+	if *f.esp != "" {
+		var err error
+		if disk, number, err = resolveMountPoint(*f.esp); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := lookupPartition(disk, number)
+	if err != nil {
+		return nil, err
+	}
+
+	lo := &LoadOption{
+		FilePathList: new(DevicePathBuilder).HardDrive(p).FilePath(*f.loader).End(),
+	}
+	lo.SetDescription(*f.label)
+	if !*f.inactive {
+		lo.Attributes |= efitypes.ActiveAttribute
+	}
+	return lo, nil
+}
+
+// createLoadOption writes lo as a new load option of the given kind
+// and prints the variable it was written to.
+func (o *outputFlags) createLoadOption(name string, k *LoadOptionKind, lo *LoadOption) (err error) {
+	c := o.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	index, err := k.Create(c, lo)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	p := o.newPrinter()
+	p.PrintFieldValue(k.VariableName(index), lo.DescriptionString())
+	_, _ = fmt.Fprint(o.writer(), p.String())
+
+	return nil
+}
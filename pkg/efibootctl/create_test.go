@@ -0,0 +1,97 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+)
+
+var errNotMounted = errors.New("not a mount point")
+
+// testESP is the partition backing the fake mount point /boot/efi.
+var testESP = &Partition{
+	Number:        2,
+	StartLBA:      2048,
+	SizeLBA:       1024000,
+	Format:        efidevicepath.GUIDPartitionFormat,
+	SignatureType: efidevicepath.GUIDSignatureType,
+	Signature:     testPartitionGUID,
+}
+
+// useFakeDisks makes the commands resolve /boot/efi to partition 2
+// of /dev/fake until the test ends.
+func useFakeDisks(t *testing.T) {
+	t.Helper()
+
+	savedResolve, savedLookup := resolveMountPoint, lookupPartition
+	resolveMountPoint = func(path string) (string, uint32, error) {
+		if path != "/boot/efi" {
+			return "", 0, fmt.Errorf("%s: %w", path, errNotMounted)
+		}
+		return "/dev/fake", testESP.Number, nil
+	}
+	lookupPartition = func(disk string, number uint32) (*Partition, error) {
+		if disk != "/dev/fake" || number != testESP.Number {
+			return nil, fmt.Errorf("%s: partition %d not found", disk, number)
+		}
+		return testESP, nil
+	}
+	t.Cleanup(func() { resolveMountPoint, lookupPartition = savedResolve, savedLookup })
+}
+
+func TestCreateESP(t *testing.T) {
+	const loader = `\EFI\fedora\shimx64.efi`
+	want := new(DevicePathBuilder).HardDrive(testESP).FilePath(loader).End()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{name: "esp", args: []string{"--esp", "/boot/efi"}},
+		{name: "disk and part", args: []string{"--disk", "/dev/fake", "--part", "2"}},
+		{name: "not a mount point", args: []string{"--esp", "/boot"}, wantErr: errNotMounted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeDisks(t)
+			s := NewMemoryVarStore()
+
+			args := append(tt.args, "--loader", loader, "--label", "Fedora")
+			if _, err := runCommand(t, s, createE, args...); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("create = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			lo, err := BootOptions.Read(s, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lo.DescriptionString(); got != "Fedora" {
+				t.Errorf("description = %q, want %q", got, "Fedora")
+			}
+			if !bytes.Equal(lo.FilePathList, want) {
+				t.Errorf("device path = % x, want % x", lo.FilePathList, want)
+			}
+		})
+	}
+}
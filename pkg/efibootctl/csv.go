@@ -0,0 +1,64 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// printCSV writes one row per boot entry with the given fields to
+// w, preceded by a header row if header is true.  All fields are
+// written if fields is nil.  Indices are formatted with
+// formatIndex.
+func printCSV(w io.Writer, entries []BootEntryInfo, header bool, fields []ListField, formatIndex func(uint16) string) error {
+	if fields == nil {
+		fields = listFieldNames
+	}
+
+	cw := csv.NewWriter(w)
+	if header {
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			row[i] = f.csvHeader()
+		}
+		_ = cw.Write(row)
+	}
+	for _, e := range entries {
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			row[i] = csvValue(e, f, formatIndex)
+		}
+		_ = cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue returns the CSV value of the given field of a boot entry.
+func csvValue(e BootEntryInfo, f ListField, formatIndex func(uint16) string) string {
+	switch f {
+	case FieldIndex:
+		return formatIndex(e.Index)
+	case FieldActive:
+		return strconv.FormatBool(e.Active)
+	case FieldLabel:
+		return e.Description
+	case FieldDevicePath:
+		return e.DevicePath
+	}
+	panic("bad field value")
+}
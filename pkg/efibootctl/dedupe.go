@@ -0,0 +1,197 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// DuplicateGroup lists Boot#### entries with identical descriptions
+// and device paths.
+type DuplicateGroup struct {
+	// Keep is the lowest index of the group.
+	Keep uint16
+
+	// Duplicates are the other indices of the group in ascending
+	// order.
+	Duplicates []uint16
+
+	// Description is the description shared by the group.
+	Description string
+}
+
+// duplicateKey identifies a load option by its serialized
+// description and device paths, so that only byte-exact copies are
+// considered duplicates.
+type duplicateKey struct {
+	description  string
+	filePathList string
+}
+
+// FindDuplicateBootEntries returns the groups of Boot#### entries
+// sharing the same description and device path.  Entries which
+// cannot be decoded are reported on stderr and skipped.
+func FindDuplicateBootEntries(c efivario.Context) ([]DuplicateGroup, error) {
+	indices, err := BootEntryIndices(c)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	var groups []DuplicateGroup
+	seen := map[duplicateKey]int{}
+	for _, index := range indices {
+		lo, err := BootOptions.Read(c, index)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+			continue
+		}
+
+		key := duplicateKey{string(lo.Description), string(lo.FilePathList)}
+		if i, ok := seen[key]; ok {
+			groups[i].Duplicates = append(groups[i].Duplicates, index)
+			continue
+		}
+		seen[key] = len(groups)
+		groups = append(groups, DuplicateGroup{Keep: index, Description: lo.DescriptionString()})
+	}
+
+	out := groups[:0]
+	for _, g := range groups {
+		if len(g.Duplicates) > 0 {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+// dedupedOrder returns the order with every duplicate replaced by
+// the entry kept in its place, so that the kept entry takes the
+// position of the first entry of its group.
+func dedupedOrder(order []uint16, groups []DuplicateGroup) []uint16 {
+	replacement := map[uint16]uint16{}
+	for _, g := range groups {
+		for _, index := range g.Duplicates {
+			replacement[index] = g.Keep
+		}
+	}
+
+	listed := map[uint16]bool{}
+	out := make([]uint16, 0, len(order))
+	for _, index := range order {
+		if keep, ok := replacement[index]; ok {
+			index = keep
+		}
+		if !listed[index] {
+			listed[index] = true
+			out = append(out, index)
+		}
+	}
+	return out
+}
+
+// RemoveDuplicateBootEntries deletes the duplicates of the given
+// groups.  The kept entries take the BootOrder position of their
+// first duplicate and BootNext is moved to the kept entry.
+func RemoveDuplicateBootEntries(c efivario.Context, groups []DuplicateGroup) error {
+	order, err := GetBootOrder(c)
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		if err := SetBootOrder(c, dedupedOrder(order, groups)); err != nil {
+			return err
+		}
+	}
+
+	_, bootNext, err := efivars.BootNext.Get(c)
+	switch {
+	case err == nil:
+		if next := dedupedOrder([]uint16{bootNext}, groups); next[0] != bootNext {
+			if err := SetBootNext(c, next[0]); err != nil {
+				return err
+			}
+		}
+	case !errors.Is(err, efivario.ErrNotFound):
+		return err
+	}
+
+	var duplicates []uint16
+	for _, g := range groups {
+		duplicates = append(duplicates, g.Duplicates...)
+	}
+	return DeleteBootEntries(c, duplicates...)
+}
+
+// duplicateSummary prints the description of a duplicated entry
+// followed by the indices of its duplicates.
+type duplicateSummary struct {
+	description string
+	duplicates  any
+}
+
+func (s duplicateSummary) PrettyPrint(p *printer.Printer) {
+	p.Print(p.Format(s.description))
+	p.Print(" duplicated by ")
+	p.Print(p.Format(s.duplicates))
+}
+
+func dedupeE(args []string) (err error) {
+	fs, out := newWriteFlagSet("dedupe")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	apply := fs.Bool("apply", false, "delete all but the lowest index of every group of duplicates")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("dedupe: unexpected arguments")
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	groups, err := FindDuplicateBootEntries(c)
+	if err != nil {
+		return fmt.Errorf("dedupe: %w", err)
+	}
+
+	p := out.newPrinter()
+	for _, g := range groups {
+		p.PrintFieldValue(out.bootEntryName(g.Keep), duplicateSummary{g.Description, out.bootIndices(g.Duplicates)})
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	if len(groups) == 0 {
+		return nil
+	}
+	if !*apply {
+		return errors.New("dedupe: duplicate entries found, pass --apply to remove them")
+	}
+
+	if err := RemoveDuplicateBootEntries(c, groups); err != nil {
+		return fmt.Errorf("dedupe: %w", err)
+	}
+	return nil
+}
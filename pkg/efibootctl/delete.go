@@ -0,0 +1,56 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+func deleteE(args []string) error {
+	return deleteLoadOptions("delete", BootOptions, args)
+}
+
+// deleteLoadOptions implements the delete command for load options
+// of the given kind.
+func deleteLoadOptions(name string, k *LoadOptionKind, args []string) (err error) {
+	fs, out := newWriteFlagSet(name)
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%s: at least one index is required", name)
+	}
+
+	indices := make([]uint16, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		index, err := out.parseIndex(arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		indices = append(indices, index)
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	if err := k.Delete(c, indices...); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+)
+
+const (
+	// macAddressSubType is the sub type of messaging device path
+	// nodes describing a network interface by its MAC address.
+	macAddressSubType efidevicepath.DevicePathSubType = 0x0b
+
+	// ipv4SubType is the sub type of messaging device path nodes
+	// describing an IPv4 connection.
+	ipv4SubType efidevicepath.DevicePathSubType = 0x0c
+
+	// ipv6SubType is the sub type of messaging device path nodes
+	// describing an IPv6 connection.
+	ipv6SubType efidevicepath.DevicePathSubType = 0x0d
+
+	// uriSubType is the sub type of messaging device path nodes
+	// holding a URI, as used for booting over HTTP.
+	uriSubType efidevicepath.DevicePathSubType = 0x18
+
+	// ifTypeEthernet is the interface type of Ethernet network
+	// interfaces as assigned by RFC 3232.
+	ifTypeEthernet = 1
+)
+
+// DevicePathRenderer returns the text representation of the body
+// of a device path node.
+type DevicePathRenderer func(data []byte) string
+
+// nodeKey identifies device path nodes by type and sub type.
+type nodeKey struct {
+	t  efidevicepath.DevicePathType
+	st efidevicepath.DevicePathSubType
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[nodeKey]DevicePathRenderer{}
+)
+
+// RegisterDevicePathRenderer registers fn to render the device path
+// nodes with the given type and sub type, e.g. vendor specific nodes.
+// It applies to all nodes efidevicepath does not decode itself and
+// overrides the renderers built into DevicePathText.  Registering a
+// nil fn removes the renderer again.
+func RegisterDevicePathRenderer(
+	t efidevicepath.DevicePathType,
+	st efidevicepath.DevicePathSubType,
+	fn DevicePathRenderer,
+) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	if fn == nil {
+		delete(renderers, nodeKey{t, st})
+		return
+	}
+	renderers[nodeKey{t, st}] = fn
+}
+
+// lookupDevicePathRenderer returns the renderer registered for the
+// given node type and sub type, if any.
+func lookupDevicePathRenderer(t efidevicepath.DevicePathType, st efidevicepath.DevicePathSubType) DevicePathRenderer {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	return renderers[nodeKey{t, st}]
+}
+
+// DevicePathText returns the text representation of the given
+// device paths like efidevicepath.DevicePaths.AllText with the
+// instances separated by spaces.  Nodes left undecoded by
+// efidevicepath are rendered by nodeText.
+func DevicePathText(paths efidevicepath.DevicePaths) string {
+	var b strings.Builder
+	first := true
+	for _, node := range paths {
+		head := node.GetHead()
+		if head.Type == efidevicepath.EndOfPathType {
+			if head.SubType != efidevicepath.EndSingleSubType {
+				break
+			}
+			b.WriteString(" ")
+			first = true
+			continue
+		}
+
+		if !first {
+			b.WriteString("/")
+		}
+		b.WriteString(nodeText(node))
+		first = false
+	}
+	return b.String()
+}
+
+// nodeText returns the text representation of a single device path
+// node.  Nodes without a renderer are rendered with their type, sub
+// type, length and body, so that a single unknown node does not hide
+// the rest of the path.
+func nodeText(node efidevicepath.DevicePath) string {
+	u, ok := node.(*efidevicepath.UnrecognizedDevicePath)
+	if !ok {
+		return node.Text()
+	}
+	if fn := lookupDevicePathRenderer(u.Type, u.SubType); fn != nil {
+		return fn(u.Data)
+	}
+
+	if u.Type == efidevicepath.MessagingType {
+		switch {
+		case u.SubType == uriSubType:
+			return fmt.Sprintf("Uri(%s)", uriText(u.Data))
+		case u.SubType == macAddressSubType && len(u.Data) == 33:
+			n := 32
+			if u.Data[32] == ifTypeEthernet {
+				n = 6
+			}
+			return fmt.Sprintf("MAC(%s,%d)", hex.EncodeToString(u.Data[:n]), u.Data[32])
+		case u.SubType == ipv4SubType && len(u.Data) >= 15:
+			mode := "DHCP"
+			if u.Data[14] != 0 {
+				mode = "Static"
+			}
+			return fmt.Sprintf("IPv4(%s,%s)", net.IP(u.Data[4:8]), mode)
+		case u.SubType == ipv6SubType && len(u.Data) >= 39:
+			return fmt.Sprintf("IPv6(%s,%s)", net.IP(u.Data[16:32]), ipv6Origin(u.Data[38]))
+		}
+	}
+	return fmt.Sprintf("UnknownNode(%d,%d,%d,%s)", u.Type, u.SubType, u.Length, hex.EncodeToString(u.Data))
+}
+
+// uriText returns the URI of a URI device path node.  The firmware
+// does not validate the URI, bytes outside of printable ASCII are
+// therefore percent-encoded, leaving existing escapes untouched.
+func uriText(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		if c <= ' ' || c >= 0x7f {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// ipv6Origin returns the name of the IPv6 address origin as used
+// in the text representation of IPv6 device path nodes.
+func ipv6Origin(origin byte) string {
+	switch origin {
+	case 0:
+		return "Static"
+	case 1:
+		return "StatelessAutoConfigure"
+	case 2:
+		return "StatefulAutoConfigure"
+	}
+	return fmt.Sprintf("%d", origin)
+}
+
+// DevicePathBuilder assembles a binary encoded device path out of
+// individual device path nodes.
+type DevicePathBuilder struct {
+	buf bytes.Buffer
+}
+
+// Node appends a generic device path node with the given type,
+// sub type and body to the device path.
+func (b *DevicePathBuilder) Node(
+	t efidevicepath.DevicePathType,
+	st efidevicepath.DevicePathSubType,
+	body []byte,
+) *DevicePathBuilder {
+	_ = binary.Write(&b.buf, binary.LittleEndian, efidevicepath.Head{
+		Type:    t,
+		SubType: st,
+		Length:  uint16(4 + len(body)),
+	})
+	b.buf.Write(body)
+	return b
+}
+
+// HardDrive appends a hard drive media device path node
+// describing the given partition.
+func (b *DevicePathBuilder) HardDrive(p *Partition) *DevicePathBuilder {
+	var body bytes.Buffer
+	_ = binary.Write(&body, binary.LittleEndian, p.Number)
+	_ = binary.Write(&body, binary.LittleEndian, p.StartLBA)
+	_ = binary.Write(&body, binary.LittleEndian, p.SizeLBA)
+	body.Write(p.Signature[:])
+	body.WriteByte(byte(p.Format))
+	body.WriteByte(byte(p.SignatureType))
+
+	return b.Node(efidevicepath.MediaType, efidevicepath.HardDriveSubType, body.Bytes())
+}
+
+// FilePath appends a file path media device path node pointing
+// to the given path.  Forward slashes are converted to the
+// backslashes used by the firmware.
+func (b *DevicePathBuilder) FilePath(path string) *DevicePathBuilder {
+	path = strings.ReplaceAll(path, "/", `\`)
+	if !strings.HasPrefix(path, `\`) {
+		path = `\` + path
+	}
+	return b.Node(efidevicepath.MediaType, efidevicepath.FilePathSubType, encodeUTF16Z(path))
+}
+
+// MACAddress appends a MAC address messaging device path node
+// describing the Ethernet interface with the given address.
+func (b *DevicePathBuilder) MACAddress(mac net.HardwareAddr) *DevicePathBuilder {
+	var body [33]byte
+	copy(body[:32], mac)
+	body[32] = ifTypeEthernet
+
+	return b.Node(efidevicepath.MessagingType, macAddressSubType, body[:])
+}
+
+// IPv4 appends an IPv4 messaging device path node with all
+// addresses left unspecified, which makes the firmware configure
+// the interface through DHCP.
+func (b *DevicePathBuilder) IPv4() *DevicePathBuilder {
+	var body [23]byte
+	return b.Node(efidevicepath.MessagingType, ipv4SubType, body[:])
+}
+
+// End terminates the device path and returns its binary encoding.
+func (b *DevicePathBuilder) End() []byte {
+	b.Node(efidevicepath.EndOfPathType, efidevicepath.EndEntireSubType, nil)
+	return b.buf.Bytes()
+}
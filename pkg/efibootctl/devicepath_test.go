@@ -0,0 +1,159 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+)
+
+// parseDevicePaths decodes the binary encoded device paths b.
+func parseDevicePaths(t *testing.T, b []byte) efidevicepath.DevicePaths {
+	t.Helper()
+
+	var paths efidevicepath.DevicePaths
+	if _, err := paths.ReadFrom(bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestDevicePathText(t *testing.T) {
+	ipv4 := make([]byte, 23)
+	copy(ipv4[4:], []byte{192, 0, 2, 1})
+	ipv4[14] = 1
+
+	// ipv6 returns the body of an IPv6 device path node with the
+	// given remote address and address origin.
+	ipv6 := func(remote string, origin byte) []byte {
+		body := make([]byte, 56)
+		copy(body[16:], net.ParseIP(remote))
+		body[38] = origin
+		return body
+	}
+
+	tests := []struct {
+		name string
+		path []byte
+		want string
+	}{
+		{
+			name: "uri",
+			path: new(DevicePathBuilder).
+				Node(efidevicepath.MessagingType, uriSubType, []byte("http://192.0.2.1/boot.efi")).
+				End(),
+			want: "Uri(http://192.0.2.1/boot.efi)",
+		},
+		{
+			name: "empty uri",
+			path: new(DevicePathBuilder).Node(efidevicepath.MessagingType, uriSubType, nil).End(),
+			want: "Uri()",
+		},
+		{
+			name: "non-UTF-8 uri",
+			path: new(DevicePathBuilder).
+				Node(efidevicepath.MessagingType, uriSubType, []byte("http://host/a\xff b%20\x1b")).
+				End(),
+			want: "Uri(http://host/a%FF%20b%20%1B)",
+		},
+		{
+			name: "ethernet mac",
+			path: new(DevicePathBuilder).MACAddress(net.HardwareAddr{0x52, 0x54, 0x00, 0x12, 0x34, 0x56}).End(),
+			want: "MAC(525400123456,1)",
+		},
+		{
+			name: "dhcp ipv4",
+			path: new(DevicePathBuilder).IPv4().End(),
+			want: "IPv4(0.0.0.0,DHCP)",
+		},
+		{
+			name: "static ipv4",
+			path: new(DevicePathBuilder).Node(efidevicepath.MessagingType, ipv4SubType, ipv4).End(),
+			want: "IPv4(192.0.2.1,Static)",
+		},
+		{
+			name: "network boot",
+			path: new(DevicePathBuilder).
+				MACAddress(net.HardwareAddr{0x52, 0x54, 0x00, 0x12, 0x34, 0x56}).
+				IPv4().
+				Node(efidevicepath.MessagingType, uriSubType, nil).
+				End(),
+			want: "MAC(525400123456,1)/IPv4(0.0.0.0,DHCP)/Uri()",
+		},
+		{
+			name: "static ipv6",
+			path: new(DevicePathBuilder).
+				Node(efidevicepath.MessagingType, ipv6SubType, ipv6("2001:db8::1", 0)).
+				End(),
+			want: "IPv6(2001:db8::1,Static)",
+		},
+		{
+			name: "stateless ipv6",
+			path: new(DevicePathBuilder).
+				Node(efidevicepath.MessagingType, ipv6SubType, ipv6("::", 1)).
+				End(),
+			want: "IPv6(::,StatelessAutoConfigure)",
+		},
+		{
+			name: "stateful ipv6",
+			path: new(DevicePathBuilder).
+				Node(efidevicepath.MessagingType, ipv6SubType, ipv6("fe80::1", 2)).
+				End(),
+			want: "IPv6(fe80::1,StatefulAutoConfigure)",
+		},
+		{
+			name: "ipv6 with unknown origin",
+			path: new(DevicePathBuilder).
+				Node(efidevicepath.MessagingType, ipv6SubType, ipv6("2001:db8::1", 7)).
+				End(),
+			want: "IPv6(2001:db8::1,7)",
+		},
+		{
+			name: "ipv6 boot",
+			path: new(DevicePathBuilder).
+				MACAddress(net.HardwareAddr{0x52, 0x54, 0x00, 0x12, 0x34, 0x56}).
+				Node(efidevicepath.MessagingType, ipv6SubType, ipv6("::", 1)).
+				Node(efidevicepath.MessagingType, uriSubType, []byte("http://[2001:db8::1]/boot.efi")).
+				End(),
+			want: "MAC(525400123456,1)/IPv6(::,StatelessAutoConfigure)/Uri(http://[2001:db8::1]/boot.efi)",
+		},
+		{
+			name: "short ipv6",
+			path: new(DevicePathBuilder).Node(efidevicepath.MessagingType, ipv6SubType, make([]byte, 38)).End(),
+			want: "UnknownNode(3,13,42," + strings.Repeat("00", 38) + ")",
+		},
+		{
+			name: "short ipv4",
+			path: new(DevicePathBuilder).Node(efidevicepath.MessagingType, ipv4SubType, []byte{1, 2}).End(),
+			want: "UnknownNode(3,12,6,0102)",
+		},
+		{
+			name: "unknown",
+			path: new(DevicePathBuilder).Node(efidevicepath.MessagingType, 0x7f, []byte{0xab}).End(),
+			want: "UnknownNode(3,127,5,ab)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DevicePathText(parseDevicePaths(t, tt.path)); got != tt.want {
+				t.Errorf("DevicePathText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// ErrBackupDiffers is returned by the diff command if the variables
+// differ from the backup.
+var ErrBackupDiffers = errors.New("the variables differ from the backup")
+
+// ChangeKind tells how a variable differs between two backups.
+type ChangeKind int
+
+const (
+	VariableAdded ChangeKind = iota + 1
+	VariableRemoved
+	VariableModified
+)
+
+// VariableChange describes a variable differing between two
+// backups.  Old is nil for added and New for removed variables.
+type VariableChange struct {
+	Name string
+	Kind ChangeKind
+	Old  *BackupVariable
+	New  *BackupVariable
+}
+
+// DiffBackups returns the variables differing between old and new,
+// BootOrder, BootNext and Timeout first followed by the Boot####
+// variables by name.
+func DiffBackups(old, new *Backup) (changes []VariableChange) {
+	index := func(b *Backup) map[string]*BackupVariable {
+		m := make(map[string]*BackupVariable, len(b.Variables))
+		for i := range b.Variables {
+			m[b.Variables[i].Name] = &b.Variables[i]
+		}
+		return m
+	}
+	oldVars, newVars := index(old), index(new)
+
+	var entries []string
+	for name := range oldVars {
+		if !isBackupSetting(name) {
+			entries = append(entries, name)
+		}
+	}
+	for name := range newVars {
+		if !isBackupSetting(name) && oldVars[name] == nil {
+			entries = append(entries, name)
+		}
+	}
+	sort.Strings(entries)
+
+	for _, name := range append(append([]string(nil), backupSettings...), entries...) {
+		o, n := oldVars[name], newVars[name]
+		switch {
+		case o == nil && n == nil:
+		case o == nil:
+			changes = append(changes, VariableChange{Name: name, Kind: VariableAdded, New: n})
+		case n == nil:
+			changes = append(changes, VariableChange{Name: name, Kind: VariableRemoved, Old: o})
+		case o.Attributes != n.Attributes || !bytes.Equal(o.Data, n.Data):
+			changes = append(changes, VariableChange{Name: name, Kind: VariableModified, Old: o, New: n})
+		}
+	}
+	return changes
+}
+
+// loadOptionFields are the fields of a decoded load option compared
+// by the diff command.
+type loadOptionFields struct {
+	Description  string
+	Attributes   LoadOptionFlags
+	DevicePath   string
+	OptionalData []byte
+}
+
+// decodeLoadOptionFields decodes the load option held by v.
+func decodeLoadOptionFields(v *BackupVariable) (*loadOptionFields, bool) {
+	if v.Index == nil {
+		return nil, false
+	}
+	var lo efitypes.LoadOption
+	if _, err := lo.ReadFrom(bytes.NewReader(v.Data)); err != nil {
+		return nil, false
+	}
+	return &loadOptionFields{
+		Description:  decodeDescription(lo.Description),
+		Attributes:   LoadOptionFlags(lo.Attributes),
+		DevicePath:   DevicePathText(lo.FilePathList),
+		OptionalData: lo.OptionalData,
+	}, true
+}
+
+// backupValue returns the value to print for v.  Values which
+// cannot be decoded are printed as a hexdump.
+func backupValue(v *BackupVariable) any {
+	switch {
+	case v.Name == efivars.BootOrderName && len(v.Data)%2 == 0:
+		order := make([]uint16, len(v.Data)/2)
+		for i := range order {
+			order[i] = binary.LittleEndian.Uint16(v.Data[2*i:])
+		}
+		return toBootIndices(order)
+	case v.Name == efivars.BootNextName && len(v.Data) == 2:
+		return BootIndex(binary.LittleEndian.Uint16(v.Data))
+	case v.Name == TimeoutName && len(v.Data) == 2:
+		return TimeoutSeconds(binary.LittleEndian.Uint16(v.Data))
+	}
+	if lo, ok := decodeLoadOptionFields(v); ok {
+		return lo.Description
+	}
+	return HexDump(v.Data)
+}
+
+// printVariableChange prints the change of a single variable.
+// Modified load options are compared field by field.
+func printVariableChange(p *printer.Printer, ch VariableChange) {
+	switch ch.Kind {
+	case VariableAdded:
+		p.PrintFieldAdded(ch.Name, backupValue(ch.New))
+		return
+	case VariableRemoved:
+		p.PrintFieldRemoved(ch.Name, backupValue(ch.Old))
+		return
+	}
+
+	if ch.Old.Attributes != ch.New.Attributes {
+		p.PrintFieldRemoved(ch.Name+" Attributes", ch.Old.Attributes)
+		p.PrintFieldAdded(ch.Name+" Attributes", ch.New.Attributes)
+	}
+	if bytes.Equal(ch.Old.Data, ch.New.Data) {
+		return
+	}
+
+	o, oldOK := decodeLoadOptionFields(ch.Old)
+	n, newOK := decodeLoadOptionFields(ch.New)
+	if !oldOK || !newOK {
+		p.PrintFieldRemoved(ch.Name, backupValue(ch.Old))
+		p.PrintFieldAdded(ch.Name, backupValue(ch.New))
+		return
+	}
+
+	field := func(name string, changed bool, old, new any) {
+		if changed {
+			p.PrintFieldRemoved(ch.Name+" "+name, old)
+			p.PrintFieldAdded(ch.Name+" "+name, new)
+		}
+	}
+	field("Description", o.Description != n.Description, o.Description, n.Description)
+	field("Attributes", o.Attributes != n.Attributes, keyword(o.Attributes.String()), keyword(n.Attributes.String()))
+	field("DevicePath", o.DevicePath != n.DevicePath, o.DevicePath, n.DevicePath)
+	field("OptionalData", !bytes.Equal(o.OptionalData, n.OptionalData),
+		optionalDataValue(o.OptionalData), optionalDataValue(n.OptionalData))
+}
+
+// optionalDataValue returns the value to print for the optional
+// data of a load option.
+func optionalDataValue(data []byte) any {
+	if len(data) == 0 {
+		return keyword("none")
+	}
+	return DataAuto.Format(data)
+}
+
+func diffE(args []string) (err error) {
+	fs, out := newOutputFlagSet("diff")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	quiet := fs.Bool("quiet", false, "do not show the progress on standard error")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("diff: exactly one backup file is required")
+	}
+
+	b, err := readBackupFile(positional[0])
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	pr := newProgress("Reading variables", *quiet)
+	live, err := exportBootVariables(c, pr.Update)
+	pr.Done()
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	changes := DiffBackups(b, live)
+
+	p := out.newPrinter()
+	for _, ch := range changes {
+		printVariableChange(p, ch)
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	if len(changes) != 0 {
+		return fmt.Errorf("diff: %w", ErrBackupDiffers)
+	}
+	return nil
+}
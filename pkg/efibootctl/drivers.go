@@ -0,0 +1,86 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// loadOptionSummary prints the description of a load option
+// followed by its device path.
+type loadOptionSummary struct {
+	lo *efitypes.LoadOption
+}
+
+func (s loadOptionSummary) PrettyPrint(p *printer.Printer) {
+	p.Print(p.Format(decodeDescription(s.lo.Description)))
+	p.Print(" ")
+	p.Print(printer.EscapeControl(DevicePathText(s.lo.FilePathList)))
+}
+
+// printLoadOptions prints the order and all load options of the
+// given kind.
+func (o *outputFlags) printLoadOptions(c efivario.Context, p *printer.Printer, k *LoadOptionKind) error {
+	order, err := k.GetOrder(c)
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		p.PrintFieldValue(k.OrderName, toBootIndices(order))
+	}
+
+	indices, err := k.Indices(c)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		lo, err := k.Get(c, index)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			continue
+		}
+
+		active := lo.Attributes&efitypes.ActiveAttribute != 0
+		p.PrintFieldValue(o.entryLabel(k.VariableName(index), active), loadOptionSummary{lo})
+	}
+	return nil
+}
+
+func driversE(args []string) (err error) {
+	fs, out := newOutputFlagSet("drivers")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	p := out.newPrinter()
+	if err := out.printLoadOptions(c, p, DriverOptions); err != nil {
+		return fmt.Errorf("drivers: %w", err)
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
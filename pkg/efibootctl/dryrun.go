@@ -0,0 +1,166 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// dryRunValue is a variable value as seen through a dryRunContext.
+type dryRunValue struct {
+	name    efivario.VariableNameItem
+	attrs   efivario.Attributes
+	data    []byte
+	deleted bool
+}
+
+// dryRunContext is an efivario.Context which passes reads through
+// to the wrapped context but only prints the writes it receives.
+// Written values are remembered, so that subsequent reads see them
+// as if they had been written.
+type dryRunContext struct {
+	efivario.Context
+
+	p       *printer.Printer
+	w       io.Writer
+	written map[string]*dryRunValue
+}
+
+var _ efivario.Context = &dryRunContext{}
+
+func newDryRunContext(c efivario.Context, p *printer.Printer, w io.Writer) *dryRunContext {
+	return &dryRunContext{Context: c, p: p, w: w, written: map[string]*dryRunValue{}}
+}
+
+func dryRunKey(name string, guid efiguid.GUID) string {
+	return name + "-" + strings.ToLower(guid.String())
+}
+
+func (c *dryRunContext) GetSizeHint(name string, guid efiguid.GUID) (int64, error) {
+	if v, ok := c.written[dryRunKey(name, guid)]; ok {
+		if v.deleted {
+			return 0, efivario.ErrNotFound
+		}
+		return int64(len(v.data)), nil
+	}
+	return c.Context.GetSizeHint(name, guid)
+}
+
+func (c *dryRunContext) Get(name string, guid efiguid.GUID, out []byte) (efivario.Attributes, int, error) {
+	if v, ok := c.written[dryRunKey(name, guid)]; ok {
+		switch {
+		case v.deleted:
+			return 0, 0, efivario.ErrNotFound
+		case len(out) < len(v.data):
+			return 0, 0, efivario.ErrInsufficientSpace
+		}
+		return v.attrs, copy(out, v.data), nil
+	}
+	return c.Context.Get(name, guid, out)
+}
+
+func (c *dryRunContext) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	key := dryRunKey(name, guid)
+	c.written[key] = &dryRunValue{
+		name:  efivario.VariableNameItem{Name: name, GUID: guid},
+		attrs: attrs,
+		data:  append([]byte(nil), value...),
+		// As with the firmware, writing an empty value removes
+		// the variable.
+		deleted: len(value) == 0,
+	}
+
+	printVariableWrite(c.p, name, guid, attrs, value)
+	return nil
+}
+
+// qualifiedName prints the name of a variable qualified with the
+// GUID of its namespace as a string with the GUID highlighted.
+type qualifiedName struct {
+	name string
+	guid efiguid.GUID
+}
+
+func (q qualifiedName) PrettyPrint(p *printer.Printer) {
+	p.ColorPrint(`"`, printer.StringQuotationColor)
+	p.ColorPrint(q.name+"-", printer.StringColor)
+	p.ColorPrint(strings.ToLower(q.guid.String()), printer.GUIDColor)
+	p.ColorPrint(`"`, printer.StringQuotationColor)
+}
+
+// printVariableWrite prints the variable about to be written.
+func printVariableWrite(p *printer.Printer, name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) {
+	p.PrintFieldValue("Set", qualifiedName{name, guid})
+	p.PrintFieldValue("Attributes", formatAttributes(attrs))
+	p.PrintFieldValue("Data", HexDump(value))
+}
+
+func (c *dryRunContext) Delete(name string, guid efiguid.GUID) error {
+	key := dryRunKey(name, guid)
+	c.written[key] = &dryRunValue{name: efivario.VariableNameItem{Name: name, GUID: guid}, deleted: true}
+
+	c.p.PrintFieldValue("Delete", qualifiedName{name, guid})
+	return nil
+}
+
+// VariableNames returns the names of the variables of the wrapped
+// context with the remembered writes and deletions applied.
+func (c *dryRunContext) VariableNames() (_ efivario.VariableNameIterator, err error) {
+	it, err := c.Context.VariableNames()
+	if err != nil {
+		return nil, err
+	}
+	defer multierr.AppendInvoke(&err, multierr.Close(it))
+
+	var items []efivario.VariableNameItem
+	for iter := it.Iter(); iter.Next(); {
+		item := iter.Value()
+		if _, ok := c.written[dryRunKey(item.Name, item.GUID)]; !ok {
+			items = append(items, item)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(c.written))
+	for key := range c.written {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if v := c.written[key]; !v.deleted {
+			items = append(items, v.name)
+		}
+	}
+	return &memoryNameIterator{items: items, pos: -1}, nil
+}
+
+// Close prints the collected writes and closes the wrapped context.
+func (c *dryRunContext) Close() (err error) {
+	defer multierr.AppendInvoke(&err, multierr.Close(c.Context))
+
+	_, _ = fmt.Fprint(c.w, c.p.String())
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// snapshotStore returns a copy of the variables held by s.
+func snapshotStore(s *MemoryVarStore) map[string]memoryVariable {
+	out := make(map[string]memoryVariable, len(s.vars))
+	for k, v := range s.vars {
+		out[dryRunKey(k.Name, k.GUID)] = memoryVariable{attrs: v.attrs, data: append([]byte(nil), v.data...)}
+	}
+	return out
+}
+
+func TestDryRunContext(t *testing.T) {
+	s := newTestStore(t, "a", "b")
+	before := snapshotStore(s)
+
+	c := newDryRunContext(s, printer.NewPrinter("", nil, true, true, true), io.Discard)
+
+	first, err := CreateBootEntry(c, newTestLoadOption("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := CreateBootEntry(c, newTestLoadOption("d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("CreateBootEntry() returned index %d twice", first)
+	}
+	if err := DeleteBootEntries(c, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	indices, err := BootEntryIndices(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint16{1, first, second}; !reflect.DeepEqual(indices, want) {
+		t.Errorf("indices seen through the dry run = %v, want %v", indices, want)
+	}
+	order, err := GetBootOrder(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint16{1, first, second}; !reflect.DeepEqual(order, want) {
+		t.Errorf("BootOrder seen through the dry run = %v, want %v", order, want)
+	}
+
+	if after := snapshotStore(s); !reflect.DeepEqual(after, before) {
+		t.Errorf("the dry run modified the store:\nbefore %v\nafter  %v", before, after)
+	}
+}
+
+func TestDryRunCommandLeavesStoreUntouched(t *testing.T) {
+	s := newTestStore(t, "a", "b")
+	before := snapshotStore(s)
+
+	out, err := runCommand(t, s, orderE, "--dry-run", "1,0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "BootOrder") {
+		t.Errorf("output = %q, want the BootOrder write", out)
+	}
+	if after := snapshotStore(s); !reflect.DeepEqual(after, before) {
+		t.Errorf("the dry run modified the store:\nbefore %v\nafter  %v", before, after)
+	}
+}
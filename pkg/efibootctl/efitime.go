@@ -0,0 +1,114 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// efiTimeSize is the size of the EFI_TIME structure.
+	efiTimeSize = 16
+
+	// unspecifiedTimezone marks an EFI_TIME in local time.
+	unspecifiedTimezone = 0x07FF
+
+	// efiTimeInDaylight is set in the Daylight field of an
+	// EFI_TIME if it is affected by daylight saving time.
+	efiTimeInDaylight = 0x02
+)
+
+// ErrInvalidEFITime is returned for EFI_TIME structures whose
+// fields are out of range.
+var ErrInvalidEFITime = errors.New("invalid EFI_TIME")
+
+// EFITime is the EFI_TIME structure used by the firmware to report
+// points in time.
+type EFITime struct {
+	Year       uint16
+	Month      uint8
+	Day        uint8
+	Hour       uint8
+	Minute     uint8
+	Second     uint8
+	Pad1       uint8
+	Nanosecond uint32
+
+	// TimeZone is the offset in minutes from UTC or
+	// unspecifiedTimezone for local time.
+	TimeZone int16
+
+	Daylight uint8
+	Pad2     uint8
+}
+
+// Time converts t into a time.Time.  Times with an unspecified time
+// zone are interpreted as local time.
+func (t EFITime) Time() (time.Time, error) {
+	switch {
+	case t.Year < 1900 || t.Year > 9999,
+		t.Month < 1 || t.Month > 12,
+		t.Day < 1 || t.Day > 31,
+		t.Hour > 23, t.Minute > 59, t.Second > 59,
+		t.Nanosecond > 999999999:
+		return time.Time{}, ErrInvalidEFITime
+	}
+
+	loc := time.Local
+	if t.TimeZone != unspecifiedTimezone {
+		if t.TimeZone < -1440 || t.TimeZone > 1440 {
+			return time.Time{}, fmt.Errorf("%w: time zone offset %d out of range", ErrInvalidEFITime, t.TimeZone)
+		}
+		loc = time.FixedZone(timezoneName(int(t.TimeZone), t.Daylight&efiTimeInDaylight != 0), int(t.TimeZone)*60)
+	}
+
+	return time.Date(
+		int(t.Year), time.Month(t.Month), int(t.Day),
+		int(t.Hour), int(t.Minute), int(t.Second), int(t.Nanosecond),
+		loc,
+	), nil
+}
+
+// timezoneName returns a name for the time zone with the given
+// offset in minutes, e.g. "UTC+01:00".
+func timezoneName(offset int, daylight bool) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	name := fmt.Sprintf("UTC%c%02d:%02d", sign, offset/60, offset%60)
+	if daylight {
+		name += " DST"
+	}
+	return name
+}
+
+// ParseEFITime decodes an EFI_TIME structure at the start of data
+// into a time.Time.
+func ParseEFITime(data []byte) (time.Time, error) {
+	if len(data) < efiTimeSize {
+		return time.Time{}, fmt.Errorf("%w: need %d bytes, got %d", ErrInvalidEFITime, efiTimeSize, len(data))
+	}
+
+	var t EFITime
+	if err := binary.Read(bytes.NewReader(data[:efiTimeSize]), binary.LittleEndian, &t); err != nil {
+		return time.Time{}, err
+	}
+	return t.Time()
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/0x5a17ed/itkit/iters/sliceit"
+	"github.com/0x5a17ed/itkit/itlib"
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// BootEntryInfo is the decoded form of a Boot#### variable.
+type BootEntryInfo struct {
+	Index        uint16
+	Description  string
+	Active       bool
+	Attributes   efitypes.Attributes
+	DevicePath   string
+	OptionalData []byte
+
+	// Err is set if the entry could not be decoded, in which case
+	// only Index is valid.
+	Err error
+}
+
+// forEachBootEntry decodes the Boot#### variables one by one in
+// the order they are enumerated by the firmware and passes them to
+// fn.
+func forEachBootEntry(c efivario.Context, fn func(e BootEntryInfo)) (err error) {
+	it, err := efivars.BootIterator(c)
+	if err != nil {
+		return err
+	}
+	defer multierr.AppendInvoke(&err, multierr.Close(it))
+
+	itlib.Apply(it.Iter(), func(be *efivars.BootEntry) {
+		_, lo, err := be.Variable.Get(c)
+		if err != nil {
+			fn(BootEntryInfo{Index: be.Index, Err: err})
+			return
+		}
+
+		fn(BootEntryInfo{
+			Index:        be.Index,
+			Description:  decodeDescription(lo.Description),
+			Active:       lo.Attributes&efitypes.ActiveAttribute != 0,
+			Attributes:   lo.Attributes,
+			DevicePath:   DevicePathText(lo.FilePathList),
+			OptionalData: lo.OptionalData,
+		})
+	})
+	return it.Err()
+}
+
+// escapeEntries returns copies of the entries with the control
+// characters in their descriptions and device paths escaped, for
+// output formats writing them verbatim.
+func escapeEntries(entries []BootEntryInfo) []BootEntryInfo {
+	out := make([]BootEntryInfo, len(entries))
+	for i, e := range entries {
+		e.Description = printer.EscapeControl(e.Description)
+		e.DevicePath = printer.EscapeControl(e.DevicePath)
+		out[i] = e
+	}
+	return out
+}
+
+// CollectBootEntries decodes all Boot#### variables in the order
+// they are enumerated by the firmware.
+func CollectBootEntries(c efivario.Context) (out []BootEntryInfo, err error) {
+	err = forEachBootEntry(c, func(e BootEntryInfo) {
+		out = append(out, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeErrorPolicy selects how boot entries which cannot be
+// decoded are handled.
+type DecodeErrorPolicy int
+
+const (
+	// ReportDecodeErrors reports undecodable entries on stderr
+	// and skips them.
+	ReportDecodeErrors DecodeErrorPolicy = iota
+
+	// IgnoreDecodeErrors silently skips undecodable entries.
+	IgnoreDecodeErrors
+
+	// AbortOnDecodeError fails on the first undecodable entry.
+	AbortOnDecodeError
+)
+
+// check returns the decode error of the given entry if the policy
+// demands to abort.  Otherwise the error is reported as configured.
+func (pol DecodeErrorPolicy) check(e BootEntryInfo) error {
+	switch pol {
+	case IgnoreDecodeErrors:
+	case AbortOnDecodeError:
+		return fmt.Errorf("%s: %w", bootEntryName(e.Index), e.Err)
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", e.Err)
+	}
+	return nil
+}
+
+// collectBootEntries returns the boot entries which could be
+// decoded and handles the others according to pol.
+func collectBootEntries(c efivario.Context, pol DecodeErrorPolicy) ([]BootEntryInfo, error) {
+	entries, err := CollectBootEntries(c)
+	if err != nil {
+		return nil, err
+	}
+
+	out := entries[:0]
+	for _, e := range entries {
+		if e.Err != nil {
+			if err := pol.check(e); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// filterBootEntries returns the entries whose description contains
+// the given text, ignoring case.
+func filterBootEntries(entries []BootEntryInfo, text string) []BootEntryInfo {
+	return sliceit.To(itlib.Filter(sliceit.In(entries), func(e BootEntryInfo) bool {
+		return e.descriptionContains(text)
+	}))
+}
+
+// descriptionContains reports whether the description contains
+// the given text, ignoring case.
+func (e BootEntryInfo) descriptionContains(text string) bool {
+	return strings.Contains(strings.ToLower(e.Description), strings.ToLower(text))
+}
+
+// sortBootEntries sorts the entries in place by the given key:
+// "index" sorts by index, "order" by the position in the given
+// BootOrder with unlisted entries last, "label" by description.
+func sortBootEntries(entries []BootEntryInfo, key string, order []uint16) error {
+	var less func(a, b BootEntryInfo) bool
+	switch key {
+	case "index":
+		less = func(a, b BootEntryInfo) bool { return a.Index < b.Index }
+	case "order":
+		position := make(map[uint16]int, len(order))
+		for i := len(order) - 1; i >= 0; i-- {
+			position[order[i]] = i
+		}
+		rank := func(e BootEntryInfo) int {
+			if pos, ok := position[e.Index]; ok {
+				return pos
+			}
+			return len(order)
+		}
+		less = func(a, b BootEntryInfo) bool { return rank(a) < rank(b) }
+	case "label":
+		less = func(a, b BootEntryInfo) bool {
+			return strings.ToLower(a.Description) < strings.ToLower(b.Description)
+		}
+	default:
+		return fmt.Errorf("unknown sort key %q", key)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+	return nil
+}
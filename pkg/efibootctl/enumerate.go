@@ -0,0 +1,208 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/0x5a17ed/itkit/itlib"
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// VariableInfo names an EFI variable present in the variable store.
+// Size and Attributes are only filled in by StatVariables.
+type VariableInfo struct {
+	Name       string
+	GUID       efiguid.GUID
+	Size       int
+	Attributes efivario.Attributes
+}
+
+// String returns the name of the variable qualified with the GUID
+// of its namespace, as accepted by ParseVariableName.
+func (v VariableInfo) String() string {
+	return v.Name + "-" + strings.ToLower(v.GUID.String())
+}
+
+// ListVariables returns all EFI variables whose name starts with
+// prefix.  Unless guid is nil only variables in the namespace with
+// the given GUID are returned.
+func ListVariables(c efivario.Context, prefix string, guid *efiguid.GUID) (out []VariableInfo, err error) {
+	it, err := c.VariableNames()
+	if err != nil {
+		return nil, err
+	}
+	defer multierr.AppendInvoke(&err, multierr.Close(it))
+
+	itlib.Apply(it.Iter(), func(vn efivario.VariableNameItem) {
+		if guid != nil && vn.GUID != *guid {
+			return
+		}
+		if strings.HasPrefix(vn.Name, prefix) {
+			out = append(out, VariableInfo{Name: vn.Name, GUID: vn.GUID})
+		}
+	})
+	return out, it.Err()
+}
+
+// StatVariables reads the size and attributes of the given variables.
+// Variables are read in full, so this is only done on request.
+func StatVariables(c efivario.Context, vars []VariableInfo) error {
+	for i := range vars {
+		attrs, size, err := statVariable(c, vars[i].Name, vars[i].GUID)
+		if err != nil {
+			return err
+		}
+		vars[i].Size, vars[i].Attributes = size, attrs
+	}
+	return nil
+}
+
+// statVariable returns the attributes and size of a variable.
+func statVariable(c efivario.Context, name string, guid efiguid.GUID) (efivario.Attributes, int, error) {
+	attrs, data, err := readVariable(c, name, guid)
+	return attrs, len(data), err
+}
+
+// sortVariables sorts the variables by the given key: name, guid or
+// size.  Variables of the same size are sorted by name.
+func sortVariables(vars []VariableInfo, key string) error {
+	var less func(a, b VariableInfo) bool
+	switch key {
+	case "name":
+		less = func(a, b VariableInfo) bool {
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.GUID.String() < b.GUID.String()
+		}
+	case "guid":
+		less = func(a, b VariableInfo) bool {
+			if a.GUID != b.GUID {
+				return a.GUID.String() < b.GUID.String()
+			}
+			return a.Name < b.Name
+		}
+	case "size":
+		less = func(a, b VariableInfo) bool {
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+			return a.Name < b.Name
+		}
+	default:
+		return fmt.Errorf("unknown sort key %q", key)
+	}
+
+	sort.SliceStable(vars, func(i, j int) bool { return less(vars[i], vars[j]) })
+	return nil
+}
+
+type jsonVariable struct {
+	Name       string  `json:"name"`
+	GUID       string  `json:"guid"`
+	Size       *int    `json:"size,omitempty"`
+	Attributes *string `json:"attributes,omitempty"`
+}
+
+// printVariablesJSON writes the given variables as a JSON array
+// to w.  With long the size and attributes are included.
+func printVariablesJSON(w io.Writer, vars []VariableInfo, long bool) error {
+	out := make([]jsonVariable, 0, len(vars))
+	for _, v := range vars {
+		jv := jsonVariable{Name: v.Name, GUID: strings.ToLower(v.GUID.String())}
+		if long {
+			size, attrs := v.Size, AttributeFlags(v.Attributes).String()
+			jv.Size, jv.Attributes = &size, &attrs
+		}
+		out = append(out, jv)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func enumerateE(args []string) (err error) {
+	fs, out := newOutputFlagSet("enumerate")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	prefix := fs.String("prefix", "", "only list variables whose name starts with `text`")
+	guid := &guidFlag{}
+	fs.Var(guid, "guid", "only list variables in the namespace with the given `GUID`")
+	sortBy := fs.String("sort", "name", "sort variables by `key`: name, guid or size, largest first")
+	long := fs.Bool("long", false, "also print the size and attributes of every variable, which requires reading it")
+	format := fs.String("format", "table", "output `format`: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("enumerate: unexpected arguments")
+	}
+	if *sortBy == "size" && !*long {
+		return errors.New("enumerate: --sort=size requires --long")
+	}
+	switch *format {
+	case "table", "json":
+	default:
+		return fmt.Errorf("enumerate: unknown format %q", *format)
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	var namespace *efiguid.GUID
+	if guid.set {
+		namespace = &guid.guid
+	}
+	vars, err := ListVariables(c, *prefix, namespace)
+	if err != nil {
+		return fmt.Errorf("enumerate: %w", err)
+	}
+	if *long {
+		if err := StatVariables(c, vars); err != nil {
+			return fmt.Errorf("enumerate: %w", err)
+		}
+	}
+	if err := sortVariables(vars, *sortBy); err != nil {
+		return fmt.Errorf("enumerate: %w", err)
+	}
+
+	if *format == "json" {
+		return printVariablesJSON(out.writer(), vars, *long)
+	}
+
+	p := out.newPrinter()
+	for _, v := range vars {
+		name := v.Name + "-" + p.Colorize(strings.ToLower(v.GUID.String()), printer.GUIDColor)
+		if *long {
+			p.Printf("%s\t%6d\t%s\n", AttributeFlags(v.Attributes), v.Size, name)
+			continue
+		}
+		p.Println(name)
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// Exit codes returned by Run, allowing scripts to branch on the
+// reason of a failure.
+const (
+	ExitOK               = 0
+	ExitFailure          = 1
+	ExitNotEFISystem     = 2
+	ExitPermissionDenied = 3
+	ExitNotFound         = 4
+)
+
+// exitCodesHelp documents the exit codes in the usage message.
+const exitCodesHelp = `Exit codes:
+  0	success
+  1	any other failure
+  2	not a UEFI system
+  3	permission denied
+  4	entry or variable not found
+`
+
+// ExitCode returns the exit code for the given error returned by
+// a command.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrNotEFISystem):
+		return ExitNotEFISystem
+	case errors.Is(err, fs.ErrPermission), errors.Is(err, ErrWriteNotPermitted):
+		return ExitPermissionDenied
+	case errors.Is(err, efivario.ErrNotFound), errors.Is(err, ErrLoadOptionNotFound):
+		return ExitNotFound
+	}
+	return ExitFailure
+}
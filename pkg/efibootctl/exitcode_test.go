@@ -0,0 +1,114 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// deniedStore is a MemoryVarStore refusing all writes the way
+// efivarfs does for unprivileged users.
+type deniedStore struct {
+	*MemoryVarStore
+}
+
+func (s deniedStore) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	return &fs.PathError{Op: "open", Path: dryRunKey(name, guid), Err: syscall.EACCES}
+}
+
+func (s deniedStore) Delete(name string, guid efiguid.GUID) error {
+	return &fs.PathError{Op: "unlinkat", Path: dryRunKey(name, guid), Err: syscall.EACCES}
+}
+
+func TestCommandErrors(t *testing.T) {
+	value := filepath.Join(t.TempDir(), "value")
+	if err := os.WriteFile(value, []byte{1}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		denied   bool
+		run      func(args []string) error
+		args     []string
+		want     string
+		wantCode int
+	}{
+		{
+			name:     "missing variable",
+			run:      getE,
+			args:     []string{"Missing"},
+			want:     "get: reading Missing: variable not found",
+			wantCode: ExitNotFound,
+		},
+		{
+			name:     "missing entry",
+			run:      deleteE,
+			args:     []string{"0007"},
+			want:     "delete: Boot0007: load option does not exist",
+			wantCode: ExitNotFound,
+		},
+		{
+			name:     "write denied",
+			denied:   true,
+			run:      orderE,
+			args:     []string{"0001,0000"},
+			want:     "order: writing BootOrder: open BootOrder-8be4df61-93ca-11d2-aa0d-00e098032b8c: permission denied",
+			wantCode: ExitPermissionDenied,
+		},
+		{
+			name:     "set denied",
+			denied:   true,
+			run:      setE,
+			args:     []string{"--force", "--from-file", value, "Var"},
+			want:     "set: Var: write not permitted, the variable might be read-only or protected by the firmware",
+			wantCode: ExitPermissionDenied,
+		},
+		{
+			name:     "delete denied",
+			denied:   true,
+			run:      deleteE,
+			args:     []string{"0001"},
+			want:     "delete: deleting Boot0001: unlinkat Boot0001-8be4df61-93ca-11d2-aa0d-00e098032b8c: permission denied",
+			wantCode: ExitPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s VarStore = newTestStore(t, "a", "b")
+			if tt.denied {
+				s = deniedStore{s.(*MemoryVarStore)}
+			}
+
+			_, err := runCommand(t, s, tt.run, tt.args...)
+			if err == nil {
+				t.Fatal("error = nil")
+			}
+			if got := err.Error(); got != tt.want {
+				t.Errorf("error = %q, want %q", got, tt.want)
+			}
+			if got := ExitCode(err); got != tt.wantCode {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.wantCode)
+			}
+		})
+	}
+}
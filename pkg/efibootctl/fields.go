@@ -0,0 +1,120 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// ListField names a column of the boot entry listing.
+type ListField string
+
+const (
+	FieldIndex      ListField = "index"
+	FieldActive     ListField = "active"
+	FieldLabel      ListField = "label"
+	FieldDevicePath ListField = "devicepath"
+)
+
+var (
+	// listFieldNames lists all fields in their default order.
+	listFieldNames = []ListField{FieldIndex, FieldActive, FieldLabel, FieldDevicePath}
+
+	// defaultListFields are the fields of the table listing.
+	defaultListFields = []ListField{FieldIndex, FieldActive, FieldLabel}
+)
+
+// listFields implements the --fields flag selecting the columns of
+// the listing.  It is nil unless the flag is given.
+type listFields []ListField
+
+func (f listFields) String() string {
+	names := make([]string, len(f))
+	for i, field := range f {
+		names[i] = string(field)
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements flag.Value and parses a comma separated list of
+// field names.
+func (f *listFields) Set(s string) error {
+	var fields listFields
+	for _, part := range strings.Split(s, ",") {
+		field, err := parseListField(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		for _, seen := range fields {
+			if seen == field {
+				return fmt.Errorf("field %q listed more than once", field)
+			}
+		}
+		fields = append(fields, field)
+	}
+	*f = fields
+	return nil
+}
+
+// parseListField parses the name of a single field.
+func parseListField(s string) (ListField, error) {
+	for _, field := range listFieldNames {
+		if string(field) == s {
+			return field, nil
+		}
+	}
+	return "", fmt.Errorf("unknown field %q, valid fields are %s", s, listFields(listFieldNames))
+}
+
+// csvHeader returns the name of the CSV column of the field.
+func (f ListField) csvHeader() string {
+	if f == FieldLabel {
+		return "description"
+	}
+	return string(f)
+}
+
+// renderColumns renders the rows of the given cells aligned in
+// columns.  Cells may be colorized, their width is measured without
+// the escape sequences.
+func renderColumns(p *printer.Printer, rows [][]string) {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := utf8.RuneCountInString(printer.StripANSI(cell)); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	for _, row := range rows {
+		var b strings.Builder
+		for i, cell := range row {
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				pad := widths[i] - utf8.RuneCountInString(printer.StripANSI(cell))
+				b.WriteString(strings.Repeat(" ", pad+1))
+			}
+		}
+		p.Println(b.String())
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+const (
+	FwVendorName          = "FwVendor"
+	FwRevisionName        = "FwRevision"
+	PlatformLangName      = "PlatformLang"
+	PlatformLangCodesName = "PlatformLangCodes"
+)
+
+// FirmwareRevision is the revision number of the firmware as
+// assigned by its vendor.
+type FirmwareRevision uint32
+
+func (r FirmwareRevision) PrettyPrint(p *printer.Printer) {
+	p.ColorPrint(fmt.Sprintf("%#08x", uint32(r)), printer.IntegerColor)
+}
+
+// FirmwareInfo identifies the firmware of the platform.  Fields
+// are left empty for variables the firmware does not expose.
+type FirmwareInfo struct {
+	Vendor            string
+	Revision          *FirmwareRevision
+	PlatformLang      string
+	PlatformLangCodes string
+}
+
+// readOptionalVariable reads the variable with the given name from
+// the global namespace.  The returned data is nil if the variable
+// does not exist.
+func readOptionalVariable(c efivario.Context, name string) ([]byte, error) {
+	_, data, err := efivario.ReadAll(c, name, efivars.GlobalVariable)
+	if err != nil {
+		if errors.Is(err, efivario.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// readASCIIZ decodes a null terminated ASCII string as used by the
+// language variables.
+func readASCIIZ(data []byte) string {
+	s := string(data)
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// GetFirmwareInfo reads the firmware identification variables.
+func GetFirmwareInfo(c efivario.Context) (*FirmwareInfo, error) {
+	info := &FirmwareInfo{}
+
+	data, err := readOptionalVariable(c, FwVendorName)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		info.Vendor = efireader.UTF16ZBytesToString(data)
+	}
+
+	data, err = readOptionalVariable(c, FwRevisionName)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		if len(data) != 4 {
+			return nil, fmt.Errorf("%s: unexpected size %d", FwRevisionName, len(data))
+		}
+		revision := FirmwareRevision(binary.LittleEndian.Uint32(data))
+		info.Revision = &revision
+	}
+
+	data, err = readOptionalVariable(c, PlatformLangName)
+	if err != nil {
+		return nil, err
+	}
+	info.PlatformLang = readASCIIZ(data)
+
+	data, err = readOptionalVariable(c, PlatformLangCodesName)
+	if err != nil {
+		return nil, err
+	}
+	info.PlatformLangCodes = readASCIIZ(data)
+
+	return info, nil
+}
+
+func firmwareInfoE(args []string) (err error) {
+	fs, out := newOutputFlagSet("firmware-info")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("firmware-info: no arguments expected")
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	info, err := GetFirmwareInfo(c)
+	if err != nil {
+		return fmt.Errorf("firmware-info: %w", err)
+	}
+
+	p := out.newPrinter()
+	if info.Vendor != "" {
+		p.PrintFieldValue("Vendor", info.Vendor)
+	}
+	if info.Revision != nil {
+		p.PrintFieldValue("Revision", *info.Revision)
+	}
+	if info.PlatformLang != "" {
+		p.PrintFieldValue(PlatformLangName, info.PlatformLang)
+	}
+	if info.PlatformLangCodes != "" {
+		p.PrintFieldValue(PlatformLangCodesName, info.PlatformLangCodes)
+	}
+
+	support, ok, err := BootOptionSupport(c)
+	if err != nil {
+		return fmt.Errorf("firmware-info: %w", err)
+	}
+	if ok {
+		p.PrintFieldValue(BootOptionSupportName, keyword(support.String()))
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
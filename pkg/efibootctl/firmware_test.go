@@ -0,0 +1,40 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyCommandsRejectArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(args []string) error
+	}{
+		{"indications", indicationsE},
+		{"firmware-info", firmwareInfoE},
+		{"secureboot", secureBootE},
+		{"get-order", getOrderE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCommand(t, newTestStore(t, "a"), tt.run, "stray")
+			if err == nil || !strings.HasPrefix(err.Error(), tt.name+": no arguments") {
+				t.Errorf("%s stray = %v, want an error rejecting the argument", tt.name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+)
+
+// guidStringLen is the length of a GUID in its canonical form.
+const guidStringLen = 36
+
+// ParseVariableName parses the name of an EFI variable which is
+// optionally qualified with the GUID of its namespace, e.g.
+// "Boot0001-8be4df61-93ca-11d2-aa0d-00e098032b8c".  Unqualified
+// names refer to the EFI global variable namespace.
+func ParseVariableName(s string) (string, efiguid.GUID, error) {
+	if i := len(s) - guidStringLen - 1; i > 0 && s[i] == '-' {
+		if guid, err := efiguid.FromString(s[i+1:]); err == nil {
+			return s[:i], guid, nil
+		}
+	}
+	if s == "" || strings.ContainsAny(s, "/\\") {
+		return "", efiguid.GUID{}, fmt.Errorf("invalid variable name %q", s)
+	}
+	return s, efivars.GlobalVariable, nil
+}
+
+// guidFlag implements the --guid flag selecting the namespace of
+// the variable named on the command line.
+type guidFlag struct {
+	guid efiguid.GUID
+	set  bool
+}
+
+// newGUIDFlag registers the --guid flag with fs.
+func newGUIDFlag(fs *flag.FlagSet) *guidFlag {
+	g := &guidFlag{}
+	fs.Var(g, "guid", "`GUID` of the vendor namespace of the variable, defaults to the EFI global variable namespace")
+	return g
+}
+
+func (g *guidFlag) String() string {
+	if g == nil || !g.set {
+		return ""
+	}
+	return strings.ToLower(g.guid.String())
+}
+
+// Set implements flag.Value.
+func (g *guidFlag) Set(s string) error {
+	guid, err := efiguid.FromString(s)
+	if err != nil {
+		return fmt.Errorf("invalid GUID %q, expected the form 8be4df61-93ca-11d2-aa0d-00e098032b8c", s)
+	}
+	g.guid, g.set = guid, true
+	return nil
+}
+
+// variableName parses the variable name s like ParseVariableName and
+// moves it into the namespace given with --guid, if any.  A name
+// qualified with a different GUID is rejected.
+func (g *guidFlag) variableName(s string) (string, efiguid.GUID, error) {
+	name, guid, err := ParseVariableName(s)
+	if err != nil || !g.set {
+		return name, guid, err
+	}
+	if name != s && guid != g.guid {
+		return "", efiguid.GUID{}, fmt.Errorf("%s: GUID does not match --guid %s", s, g)
+	}
+	return name, g.guid, nil
+}
+
+func getE(args []string) (err error) {
+	fs, out := newOutputFlagSet("get")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	asTime := fs.Bool("time", false, "decode the value as an EFI_TIME timestamp")
+	guid := newGUIDFlag(fs)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("get: exactly one variable name is required")
+	}
+
+	name, namespace, err := guid.variableName(positional[0])
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	attrs, data, err := readVariable(c, name, namespace)
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+
+	p := out.newPrinter()
+	p.PrintFieldValue("Variable", qualifiedName{name, namespace})
+	p.PrintFieldValue("Attributes", AttributeFlags(attrs))
+	p.PrintFieldValue("Size", len(data))
+	if *asTime {
+		t, err := ParseEFITime(data)
+		if err != nil {
+			return fmt.Errorf("get: %s: %w", name, err)
+		}
+		p.PrintFieldValue("Time", t)
+	}
+	p.PrintFieldValue("Data", HexDump(data))
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
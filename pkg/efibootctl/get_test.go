@@ -0,0 +1,99 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+// noHintStore is a MemoryVarStore which cannot tell the size of its
+// variables in advance.
+type noHintStore struct {
+	*MemoryVarStore
+}
+
+func (s noHintStore) GetSizeHint(name string, guid efiguid.GUID) (int64, error) {
+	return 0, nil
+}
+
+// newLargeVariableStore returns a MemoryVarStore holding the
+// variable Big with a value of the given size.
+func newLargeVariableStore(t *testing.T, size int) (*MemoryVarStore, []byte) {
+	t.Helper()
+
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	s := NewMemoryVarStore()
+	if err := s.Set("Big", efivars.GlobalVariable, defaultAttributes, data); err != nil {
+		t.Fatal(err)
+	}
+	return s, data
+}
+
+func TestReadVariable(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		noHint bool
+	}{
+		{name: "small", size: 16},
+		{name: "larger than 2 KiB", size: 3000},
+		{name: "larger than 4 KiB", size: 10000},
+		{name: "larger than 2 KiB without size hint", size: 3000, noHint: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, want := newLargeVariableStore(t, tt.size)
+			var c VarStore = s
+			if tt.noHint {
+				c = noHintStore{s}
+			}
+
+			attrs, got, err := readVariable(c, "Big", efivars.GlobalVariable)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if attrs != defaultAttributes {
+				t.Errorf("attributes = %v, want %v", attrs, defaultAttributes)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("value of %d bytes does not match the %d bytes written", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestGetLargeVariable(t *testing.T) {
+	s, _ := newLargeVariableStore(t, 3000)
+	out, err := runCommand(t, s, getE, "Big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Attributes: NV,BS,RT",
+		"00000bb0  b0 b1 b2 b3 b4 b5 b6 b7",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("get output lacks %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+)
+
+const (
+	// gptHeaderLBA is the logical block holding the primary GPT
+	// header.
+	gptHeaderLBA = 1
+
+	// gptMinHeaderSize and gptMinEntrySize are the sizes of the
+	// GPT header and partition entries defined by the UEFI
+	// specification.  Later revisions may only grow them.
+	gptMinHeaderSize = 92
+	gptMinEntrySize  = 128
+
+	// gptMaxEntries and gptMaxEntrySize bound the partition entry
+	// array, so that a corrupt header cannot make it arbitrarily
+	// large.  Partitioning tools create 128 entries of 128 bytes.
+	gptMaxEntries   = 1024
+	gptMaxEntrySize = 4096
+)
+
+var (
+	// gptSignature starts every GPT header.
+	gptSignature = []byte("EFI PART")
+
+	// ErrNoGPT is returned for disks without a GUID partition
+	// table.
+	ErrNoGPT = errors.New("no GUID partition table")
+)
+
+// gptHeader holds the fields of a GPT header needed to locate the
+// partition entries.
+type gptHeader struct {
+	EntriesLBA uint64
+	NumEntries uint32
+	EntrySize  uint32
+	EntriesCRC uint32
+}
+
+// readGPTHeader reads and validates the primary GPT header of a disk
+// with the given logical block size.
+func readGPTHeader(r io.ReaderAt, blockSize uint64) (*gptHeader, error) {
+	block := make([]byte, blockSize)
+	if _, err := r.ReadAt(block, gptHeaderLBA*int64(blockSize)); err != nil {
+		return nil, fmt.Errorf("gpt: %w", err)
+	}
+	if !bytes.HasPrefix(block, gptSignature) {
+		return nil, ErrNoGPT
+	}
+
+	size := binary.LittleEndian.Uint32(block[12:])
+	if size < gptMinHeaderSize || uint64(size) > blockSize {
+		return nil, fmt.Errorf("gpt: invalid header size %d", size)
+	}
+
+	// The header checksum is computed with its own field zeroed.
+	header := append([]byte(nil), block[:size]...)
+	want := binary.LittleEndian.Uint32(header[16:])
+	binary.LittleEndian.PutUint32(header[16:], 0)
+	if crc32.ChecksumIEEE(header) != want {
+		return nil, errors.New("gpt: header checksum mismatch")
+	}
+
+	h := &gptHeader{
+		EntriesLBA: binary.LittleEndian.Uint64(header[72:]),
+		NumEntries: binary.LittleEndian.Uint32(header[80:]),
+		EntrySize:  binary.LittleEndian.Uint32(header[84:]),
+		EntriesCRC: binary.LittleEndian.Uint32(header[88:]),
+	}
+	if h.EntrySize < gptMinEntrySize || h.EntrySize > gptMaxEntrySize || h.EntrySize%8 != 0 {
+		return nil, fmt.Errorf("gpt: invalid partition entry size %d", h.EntrySize)
+	}
+	if h.NumEntries > gptMaxEntries {
+		return nil, fmt.Errorf("gpt: too many partition entries %d", h.NumEntries)
+	}
+	if h.EntriesLBA > math.MaxInt64/blockSize {
+		return nil, fmt.Errorf("gpt: invalid partition entries LBA %d", h.EntriesLBA)
+	}
+	return h, nil
+}
+
+// ReadGPTPartition reads the partition with the given number,
+// starting with 1, from the GUID partition table of a disk with the
+// given logical block size.  The partition is identified by its
+// unique partition GUID, which stays the same when partitions are
+// reordered.  ErrNoGPT is returned for disks without a GPT.
+func ReadGPTPartition(r io.ReaderAt, blockSize uint64, number uint32) (*Partition, error) {
+	h, err := readGPTHeader(r, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	if number == 0 || number > h.NumEntries {
+		return nil, fmt.Errorf("gpt: partition %d not found", number)
+	}
+
+	entries := make([]byte, uint64(h.NumEntries)*uint64(h.EntrySize))
+	if _, err := r.ReadAt(entries, int64(h.EntriesLBA*blockSize)); err != nil {
+		return nil, fmt.Errorf("gpt: %w", err)
+	}
+	if crc32.ChecksumIEEE(entries) != h.EntriesCRC {
+		return nil, errors.New("gpt: partition entries checksum mismatch")
+	}
+
+	entry := entries[uint64(number-1)*uint64(h.EntrySize):][:h.EntrySize]
+	if bytes.Equal(entry[:16], make([]byte, 16)) {
+		return nil, fmt.Errorf("gpt: partition %d not found", number)
+	}
+
+	first := binary.LittleEndian.Uint64(entry[32:])
+	last := binary.LittleEndian.Uint64(entry[40:])
+	if last < first {
+		return nil, fmt.Errorf("gpt: partition %d ends before it starts", number)
+	}
+
+	p := &Partition{
+		Number:        number,
+		StartLBA:      first,
+		SizeLBA:       last - first + 1,
+		Format:        efidevicepath.GUIDPartitionFormat,
+		SignatureType: efidevicepath.GUIDSignatureType,
+	}
+	copy(p.Signature[:], entry[16:32])
+	return p, nil
+}
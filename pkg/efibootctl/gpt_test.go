@@ -0,0 +1,202 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"reflect"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+)
+
+const testBlockSize = 512
+
+var testPartitionGUID = [16]byte{
+	0x28, 0x73, 0x2a, 0xc1, 0x1f, 0xf8, 0xd2, 0x11,
+	0xba, 0x4b, 0x00, 0xa0, 0xc9, 0x3e, 0xc9, 0x3b,
+}
+
+// newTestGPTImage returns a disk image with a GPT holding four
+// partition entries of which only the first is in use, passing it
+// to modify before the checksums are computed.
+func newTestGPTImage(modify func(header, entries []byte)) []byte {
+	image := make([]byte, 4*testBlockSize)
+
+	entries := image[2*testBlockSize:][:4*gptMinEntrySize]
+	copy(entries[0:], []byte{0xa2, 0xa0, 0xd0, 0xeb, 0xe5, 0xb9, 0x33, 0x44, 0x87, 0xc0, 0x68, 0xb6, 0xb7, 0x26, 0x99, 0xc7})
+	copy(entries[16:], testPartitionGUID[:])
+	binary.LittleEndian.PutUint64(entries[32:], 2048)
+	binary.LittleEndian.PutUint64(entries[40:], 4095)
+
+	header := image[testBlockSize:][:gptMinHeaderSize]
+	copy(header, gptSignature)
+	binary.LittleEndian.PutUint32(header[8:], 0x00010000)
+	binary.LittleEndian.PutUint32(header[12:], gptMinHeaderSize)
+	binary.LittleEndian.PutUint64(header[72:], 2)
+	binary.LittleEndian.PutUint32(header[80:], 4)
+	binary.LittleEndian.PutUint32(header[84:], gptMinEntrySize)
+
+	if modify != nil {
+		modify(header, entries)
+	}
+
+	binary.LittleEndian.PutUint32(header[88:], crc32.ChecksumIEEE(entries))
+	binary.LittleEndian.PutUint32(header[16:], crc32.ChecksumIEEE(header))
+	return image
+}
+
+func TestReadGPTPartition(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   []byte
+		number  uint32
+		want    *Partition
+		wantErr bool
+		is      error
+	}{
+		{
+			name:   "partition",
+			image:  newTestGPTImage(nil),
+			number: 1,
+			want: &Partition{
+				Number:        1,
+				StartLBA:      2048,
+				SizeLBA:       2048,
+				Format:        efidevicepath.GUIDPartitionFormat,
+				SignatureType: efidevicepath.GUIDSignatureType,
+				Signature:     testPartitionGUID,
+			},
+		},
+		{
+			name:    "unused entry",
+			image:   newTestGPTImage(nil),
+			number:  2,
+			wantErr: true,
+		},
+		{
+			name:    "number 0",
+			image:   newTestGPTImage(nil),
+			number:  0,
+			wantErr: true,
+		},
+		{
+			name:    "number beyond the table",
+			image:   newTestGPTImage(nil),
+			number:  5,
+			wantErr: true,
+		},
+		{
+			name:    "no GPT",
+			image:   make([]byte, 4*testBlockSize),
+			number:  1,
+			wantErr: true,
+			is:      ErrNoGPT,
+		},
+		{
+			name:    "truncated header",
+			image:   newTestGPTImage(nil)[:testBlockSize+gptMinHeaderSize],
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name:    "truncated entries",
+			image:   newTestGPTImage(nil)[:2*testBlockSize+gptMinEntrySize],
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name:    "short header size",
+			image:   newTestGPTImage(func(h, _ []byte) { binary.LittleEndian.PutUint32(h[12:], 91) }),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name: "header checksum mismatch",
+			image: func() []byte {
+				image := newTestGPTImage(nil)
+				image[testBlockSize+80]++
+				return image
+			}(),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name: "entries checksum mismatch",
+			image: func() []byte {
+				image := newTestGPTImage(nil)
+				image[2*testBlockSize+32]++
+				return image
+			}(),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name:    "invalid entry size",
+			image:   newTestGPTImage(func(h, _ []byte) { binary.LittleEndian.PutUint32(h[84:], 100) }),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name:    "entry size not a multiple of 8",
+			image:   newTestGPTImage(func(h, _ []byte) { binary.LittleEndian.PutUint32(h[84:], 132) }),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name:    "oversized entries",
+			image:   newTestGPTImage(func(h, _ []byte) { binary.LittleEndian.PutUint32(h[84:], gptMaxEntrySize+8) }),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name:    "too many entries",
+			image:   newTestGPTImage(func(h, _ []byte) { binary.LittleEndian.PutUint32(h[80:], 1<<31) }),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name:    "entries LBA overflows the offset",
+			image:   newTestGPTImage(func(h, _ []byte) { binary.LittleEndian.PutUint64(h[72:], 1<<62) }),
+			number:  1,
+			wantErr: true,
+		},
+		{
+			name: "partition ends before it starts",
+			image: newTestGPTImage(func(_, e []byte) {
+				binary.LittleEndian.PutUint64(e[40:], 1024)
+			}),
+			number:  1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadGPTPartition(bytes.NewReader(tt.image), testBlockSize, tt.number)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadGPTPartition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("ReadGPTPartition() error = %v, want %v", err, tt.is)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadGPTPartition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+// newTestLoadOption returns an active load option with the given
+// description pointing at a loader.
+func newTestLoadOption(description string) *LoadOption {
+	lo := &LoadOption{
+		Attributes:   efitypes.ActiveAttribute,
+		FilePathList: new(DevicePathBuilder).FilePath(`\EFI\test\loader.efi`).End(),
+	}
+	lo.SetDescription(description)
+	return lo
+}
+
+// newTestStore returns a MemoryVarStore holding a boot entry for
+// every given description, in the BootOrder in the given order.
+// BootCurrent points at the first entry.
+func newTestStore(t *testing.T, descriptions ...string) *MemoryVarStore {
+	t.Helper()
+
+	s := NewMemoryVarStore()
+	for _, d := range descriptions {
+		if _, err := BootOptions.Create(s, newTestLoadOption(d)); err != nil {
+			t.Fatalf("creating %q: %v", d, err)
+		}
+	}
+	if err := efivars.BootCurrent.Set(s, 0); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// useStore makes the commands operate on s until the test ends.
+func useStore(t *testing.T, s VarStore) {
+	t.Helper()
+
+	saved := openVarStore
+	openVarStore = func() efivario.Context { return s }
+	t.Cleanup(func() { openVarStore = saved })
+}
+
+// runCommand runs the command against s with its output written to
+// a file and returns the output.
+func runCommand(t *testing.T, s VarStore, run func(args []string) error, args ...string) (string, error) {
+	t.Helper()
+
+	useStore(t, s)
+	path := filepath.Join(t.TempDir(), "output")
+	err := run(append([]string{"--output", path}, args...))
+
+	data, readErr := os.ReadFile(path)
+	if readErr != nil && !os.IsNotExist(readErr) {
+		t.Fatal(readErr)
+	}
+	return string(data), err
+}
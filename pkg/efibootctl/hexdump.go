@@ -0,0 +1,118 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// HexDump prints binary data as a classic offset, hex and ASCII
+// dump starting on a new line.
+type HexDump []byte
+
+func (d HexDump) PrettyPrint(p *printer.Printer) {
+	for _, line := range strings.SplitAfter(hex.Dump(d), "\n") {
+		if line == "" {
+			continue
+		}
+		p.Print("\n  ")
+		p.ColorPrint(strings.TrimSuffix(line, "\n"), printer.IntegerColor)
+	}
+}
+
+// DataFormat selects how optional data is printed.
+type DataFormat int
+
+const (
+	// DataAuto prints optional data as text if it decodes as
+	// printable utf16 text and as a hexdump otherwise.
+	DataAuto DataFormat = iota
+
+	// DataText always prints optional data as utf16 text.
+	DataText
+
+	// DataHex always prints optional data as a hexdump.
+	DataHex
+)
+
+var dataFormatNames = map[DataFormat]string{
+	DataAuto: "auto",
+	DataText: "text",
+	DataHex:  "hex",
+}
+
+func (f DataFormat) String() string {
+	if name, ok := dataFormatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("DataFormat(%d)", int(f))
+}
+
+// Set implements flag.Value.
+func (f *DataFormat) Set(s string) error {
+	for format, name := range dataFormatNames {
+		if name == s {
+			*f = format
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid data format %q, expected text, hex or auto", s)
+}
+
+// decodeTextData decodes data as a utf16 string as commonly used
+// for kernel command lines.  The returned bool is false if data
+// does not decode cleanly or contains control characters.
+func decodeTextData(data []byte) (string, bool) {
+	if len(data) == 0 || len(data)%2 != 0 {
+		return "", false
+	}
+
+	// Strip the optional Null terminator.
+	for len(data) >= 2 && data[len(data)-2] == 0 && data[len(data)-1] == 0 {
+		data = data[:len(data)-2]
+	}
+
+	s := efireader.UTF16BytesToString(data)
+	if s == "" {
+		return "", false
+	}
+	for _, r := range s {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\t') {
+			return "", false
+		}
+	}
+	return s, true
+}
+
+// Format returns the value to print for the given optional data.
+func (f DataFormat) Format(data []byte) any {
+	switch f {
+	case DataText:
+		return efireader.UTF16ZBytesToString(data)
+	case DataAuto:
+		if s, ok := decodeTextData(data); ok {
+			return s
+		}
+	}
+	return HexDump(data)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// Hide sets the hidden attribute of the boot entry with the given
+// index, which makes the firmware leave it out of its boot menu.
+func Hide(c efivario.Context, index uint16) error {
+	return setBootEntryAttribute(c, index, efitypes.HiddenAttribute, true)
+}
+
+// Unhide clears the hidden attribute of the boot entry with the
+// given index.
+func Unhide(c efivario.Context, index uint16) error {
+	return setBootEntryAttribute(c, index, efitypes.HiddenAttribute, false)
+}
+
+func hideE(args []string) error {
+	return activationE("hide", Hide, args)
+}
+
+func unhideE(args []string) error {
+	return activationE("unhide", Unhide, args)
+}
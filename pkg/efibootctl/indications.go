@@ -0,0 +1,211 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+)
+
+const (
+	OsIndicationsName          = "OsIndications"
+	OsIndicationsSupportedName = "OsIndicationsSupported"
+)
+
+// OsIndications is a bitmask of features the OS requests from the
+// firmware, or the firmware supports.
+//
+// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1347148>
+type OsIndications uint64
+
+const (
+	BootToFirmwareUI             OsIndications = 0x0000000000000001
+	TimestampRevocation          OsIndications = 0x0000000000000002
+	FileCapsuleDeliverySupported OsIndications = 0x0000000000000004
+	FMPCapsuleSupported          OsIndications = 0x0000000000000008
+	CapsuleResultVarSupported    OsIndications = 0x0000000000000010
+	StartOSRecovery              OsIndications = 0x0000000000000020
+	StartPlatformRecovery        OsIndications = 0x0000000000000040
+	JSONConfigDataRefresh        OsIndications = 0x0000000000000080
+)
+
+var osIndicationNames = []struct {
+	bit  OsIndications
+	name string
+}{
+	{BootToFirmwareUI, "BootToFirmwareUI"},
+	{TimestampRevocation, "TimestampRevocation"},
+	{FileCapsuleDeliverySupported, "FileCapsuleDeliverySupported"},
+	{FMPCapsuleSupported, "FMPCapsuleSupported"},
+	{CapsuleResultVarSupported, "CapsuleResultVarSupported"},
+	{StartOSRecovery, "StartOSRecovery"},
+	{StartPlatformRecovery, "StartPlatformRecovery"},
+	{JSONConfigDataRefresh, "JSONConfigDataRefresh"},
+}
+
+// OsIndication describes a single indication supported by the
+// firmware.
+type OsIndication struct {
+	Bit       OsIndications
+	Name      string
+	Requested bool
+}
+
+// DecodeOsIndications returns the indications set in supported
+// in ascending bit order, marking those set in requested.  Unknown
+// bits are named by their value.
+func DecodeOsIndications(supported, requested OsIndications) (out []OsIndication) {
+	for i := 0; i < 64; i++ {
+		bit := OsIndications(1) << i
+		if supported&bit == 0 {
+			continue
+		}
+
+		name := fmt.Sprintf("%#x", uint64(bit))
+		for _, n := range osIndicationNames {
+			if n.bit == bit {
+				name = n.name
+			}
+		}
+		out = append(out, OsIndication{Bit: bit, Name: name, Requested: requested&bit != 0})
+	}
+	return
+}
+
+// readOsIndications reads the bitmask variable with the given name.
+// A missing variable is reported as an empty bitmask.
+func readOsIndications(c efivario.Context, name string) (OsIndications, error) {
+	data, err := readOptionalVariable(c, name)
+	if err != nil || data == nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("%s: unexpected size %d", name, len(data))
+	}
+	return OsIndications(binary.LittleEndian.Uint64(data)), nil
+}
+
+// GetOsIndicationsSupported returns the indications supported by
+// the firmware.
+func GetOsIndicationsSupported(c efivario.Context) (OsIndications, error) {
+	return readOsIndications(c, OsIndicationsSupportedName)
+}
+
+// GetOsIndications returns the indications currently requested by
+// the OS.
+func GetOsIndications(c efivario.Context) (OsIndications, error) {
+	return readOsIndications(c, OsIndicationsName)
+}
+
+// ErrIndicationNotSupported is returned when requesting an
+// indication not advertised in OsIndicationsSupported.
+var ErrIndicationNotSupported = errors.New("indication not supported by the firmware")
+
+// SetOsIndications replaces the indications requested by the OS.
+func SetOsIndications(c efivario.Context, v OsIndications) error {
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], uint64(v))
+	return c.Set(OsIndicationsName, efivars.GlobalVariable, defaultAttributes, data[:])
+}
+
+// RequestOsIndication sets or clears the given indication bits in
+// OsIndications, leaving all other bits untouched.  Setting bits
+// not advertised by the firmware fails with
+// ErrIndicationNotSupported.
+func RequestOsIndication(c efivario.Context, bits OsIndications, request bool) error {
+	if request {
+		supported, err := GetOsIndicationsSupported(c)
+		if err != nil {
+			return err
+		}
+		if supported&bits != bits {
+			return ErrIndicationNotSupported
+		}
+	}
+
+	current, err := GetOsIndications(c)
+	if err != nil {
+		return err
+	}
+
+	updated := current &^ bits
+	if request {
+		updated |= bits
+	}
+
+	if updated == current {
+		return nil
+	}
+	return SetOsIndications(c, updated)
+}
+
+func indicationsE(args []string) (err error) {
+	fs, out := newOutputFlagSet("indications")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("indications: no arguments expected")
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	supported, err := GetOsIndicationsSupported(c)
+	if err != nil {
+		return fmt.Errorf("indications: %w", err)
+	}
+
+	requested, err := GetOsIndications(c)
+	if err != nil {
+		return fmt.Errorf("indications: %w", err)
+	}
+
+	p := out.newPrinter()
+	for _, ind := range DecodeOsIndications(supported, requested) {
+		p.PrintFieldValue(ind.Name, ind.Requested)
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
+
+func rebootToFirmwareE(args []string) (err error) {
+	fs, out := newWriteFlagSet("reboot-to-firmware")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	clear := fs.Bool("clear", false, "cancel a pending request to boot into the firmware setup")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("reboot-to-firmware: no arguments expected")
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	if err := RequestOsIndication(c, BootToFirmwareUI, !*clear); err != nil {
+		return fmt.Errorf("reboot-to-firmware: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+)
+
+// jsonSchemaVersion is the version of the JSON document written by
+// --json.  It has to be incremented whenever a field is renamed,
+// removed or changes its format.
+const jsonSchemaVersion = 1
+
+// jsonEntry is a single boot entry as written by --json and as
+// seen by --template.  The doc tags end up in the JSON Schema
+// printed by the json-schema command.
+type jsonEntry struct {
+	Index        BootIndex `json:"index" doc:"index of the Boot#### variable"`
+	Description  string    `json:"description" doc:"description shown in the firmware boot menu"`
+	Active       bool      `json:"active" doc:"whether the entry is active"`
+	DevicePath   string    `json:"devicePath" doc:"text representation of the device path of the loader"`
+	OptionalData []byte    `json:"optionalData" doc:"optional data passed to the loader"`
+
+	// Current is only set in JSON documents, as it requires
+	// knowing BootCurrent.
+	Current bool `json:"current,omitempty" doc:"whether the system was booted from the entry"`
+}
+
+func newJSONEntry(e BootEntryInfo) jsonEntry {
+	return jsonEntry{
+		Index:        BootIndex(e.Index),
+		Description:  e.Description,
+		Active:       e.Active,
+		DevicePath:   e.DevicePath,
+		OptionalData: e.OptionalData,
+	}
+}
+
+// jsonDocument is the boot manager state as written by --json.  It
+// is also the data --template is executed against.
+type jsonDocument struct {
+	SchemaVersion int         `json:"schemaVersion" doc:"version of this document format"`
+	BootCurrent   BootIndex   `json:"bootCurrent" doc:"index of the entry the system was booted from"`
+	BootNext      *BootIndex  `json:"bootNext" doc:"index of the entry to boot next, if set"`
+	BootOrder     []BootIndex `json:"bootOrder" doc:"indices of the entries in the order they are tried"`
+	Entries       []jsonEntry `json:"entries" doc:"the boot entries"`
+}
+
+// printJSON writes the boot manager state with the given boot
+// entries as a JSON document to w.
+func printJSON(c efivario.Context, w io.Writer, entries []BootEntryInfo, bootOrder []uint16) error {
+	doc, err := newJSONDocument(c, entries, bootOrder)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// newJSONDocument collects the boot manager state with the given
+// boot entries and BootOrder, as read by listOptions.load.
+func newJSONDocument(c efivario.Context, entries []BootEntryInfo, bootOrder []uint16) (*jsonDocument, error) {
+	doc := &jsonDocument{
+		SchemaVersion: jsonSchemaVersion,
+		BootOrder:     []BootIndex{},
+		Entries:       []jsonEntry{},
+	}
+
+	_, bootNext, err := efivars.BootNext.Get(c)
+	if err != nil {
+		if !errors.Is(err, efivario.ErrNotFound) {
+			return nil, err
+		}
+	} else {
+		index := BootIndex(bootNext)
+		doc.BootNext = &index
+	}
+
+	_, bootCurrent, err := efivars.BootCurrent.Get(c)
+	if err != nil {
+		return nil, err
+	}
+	doc.BootCurrent = BootIndex(bootCurrent)
+
+	doc.BootOrder = append(doc.BootOrder, toBootIndices(bootOrder)...)
+
+	for _, e := range entries {
+		je := newJSONEntry(e)
+		je.Current = e.Index == bootCurrent
+		doc.Entries = append(doc.Entries, je)
+	}
+	return doc, nil
+}
+
+// printJSONLines writes one JSON object per boot entry to w.
+func printJSONLines(w io.Writer, entries []BootEntryInfo) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(newJSONEntry(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamJSONLines writes one JSON object per boot entry to w as
+// soon as it has been read, skipping entries whose description
+// does not contain grep.  Entries which fail to decode are handled
+// according to pol.
+func streamJSONLines(c efivario.Context, w io.Writer, grep string, pol DecodeErrorPolicy) error {
+	enc := json.NewEncoder(w)
+
+	var encErr error
+	err := forEachBootEntry(c, func(e BootEntryInfo) {
+		switch {
+		case encErr != nil:
+			return
+		case e.Err != nil:
+			encErr = pol.check(e)
+			return
+		case grep != "" && !e.descriptionContains(grep):
+			return
+		}
+		encErr = enc.Encode(newJSONEntry(e))
+	})
+	return multierr.Append(err, encErr)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+func TestListJSONWithoutBootOrder(t *testing.T) {
+	s := newTestStore(t, "a")
+	if err := s.Delete(efivars.BootOrderName, efivars.GlobalVariable); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := runCommand(t, s, listE, "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc jsonDocument
+	if err := json.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.BootOrder) != 0 || len(doc.Entries) != 1 {
+		t.Errorf("bootOrder = %v, entries = %d, want no order and one entry", doc.BootOrder, len(doc.Entries))
+	}
+}
+
+func TestListJSONWithOddSizedBootOrder(t *testing.T) {
+	s := newTestStore(t, "a", "b")
+	odd := []byte{1, 0, 0, 0, 7}
+	if err := s.Set(efivars.BootOrderName, efivars.GlobalVariable, defaultAttributes, odd); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := runCommand(t, s, listE, "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc jsonDocument
+	if err := json.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if want := []BootIndex{1, 0}; !reflect.DeepEqual(doc.BootOrder, want) {
+		t.Errorf("bootOrder = %v, want %v", doc.BootOrder, want)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"golang.org/x/text/language"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// labelTranslations holds the translated field labels, keyed by
+// language.  Names of EFI variables are never translated.
+var labelTranslations = map[language.Tag]map[string]string{
+	language.German: {
+		"After":        "Nachher",
+		"Attributes":   "Attribute",
+		"Before":       "Vorher",
+		"Dangling":     "Verwaist",
+		"Data":         "Daten",
+		"Delete":       "Löschen",
+		"OptionalData": "Optionale Daten",
+		"Set":          "Setzen",
+		"Size":         "Größe",
+		"Unlisted":     "Nicht gelistet",
+	},
+}
+
+func init() {
+	for tag, labels := range labelTranslations {
+		if err := printer.RegisterLabels(tag, labels); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// listOptions holds the flags selecting and rendering the boot
+// entries shared by the list and watch commands.
+type listOptions struct {
+	showData  bool
+	dataAs    DataFormat
+	grep      string
+	sortBy    string
+	quiet     bool
+	strict    bool
+	noHeaders bool
+	rawOrder  bool
+	truncate  uint
+	sinceBoot bool
+	marker    string
+	fields    listFields
+}
+
+// newListOptions registers the shared listing flags with fs.
+func newListOptions(fs *flag.FlagSet) *listOptions {
+	lo := &listOptions{}
+	fs.BoolVar(&lo.showData, "show-data", false, "print the optional data of each entry")
+	fs.Var(&lo.dataAs, "data-as", "print optional data as `format` text, hex or auto")
+	fs.StringVar(&lo.grep, "grep", "", "only print entries whose description contains `text`, ignoring case; BootOrder is still printed in full")
+	fs.StringVar(&lo.sortBy, "sort", "index", "sort entries by `key`: index, order or label")
+	fs.BoolVar(&lo.quiet, "quiet", false, "do not report entries which cannot be decoded")
+	fs.BoolVar(&lo.strict, "strict", false, "fail on the first entry which cannot be decoded")
+	fs.BoolVar(&lo.rawOrder, "show-raw-order", false, "also print the undecoded bytes of BootOrder")
+	fs.UintVar(&lo.truncate, "truncate", 0, "shorten descriptions longer than `n` characters in the table, 0 to never shorten them")
+	fs.BoolVar(&lo.sinceBoot, "since-boot", false, "mark the entry the system was booted from, as given by BootCurrent")
+	fs.StringVar(&lo.marker, "current-marker", "→", "`text` marking the entry the system was booted from with --since-boot")
+	fs.Var(&lo.fields, "fields", "comma separated `fields` to print: index, active, label and devicepath, default index,active,label")
+	fs.BoolVar(&lo.noHeaders, "no-headers", false, "only print the entry rows, without BootNext, BootCurrent, Timeout, Secure Boot state and BootOrder")
+	return lo
+}
+
+// policy returns the DecodeErrorPolicy selected by --quiet and
+// --strict.
+func (lo *listOptions) policy() (DecodeErrorPolicy, error) {
+	switch {
+	case lo.quiet && lo.strict:
+		return 0, errors.New("--quiet and --strict are mutually exclusive")
+	case lo.quiet:
+		return IgnoreDecodeErrors, nil
+	case lo.strict:
+		return AbortOnDecodeError, nil
+	}
+	return ReportDecodeErrors, nil
+}
+
+// load collects, filters and sorts the boot entries and returns
+// them together with the BootOrder.
+func (lo *listOptions) load(c efivario.Context, pol DecodeErrorPolicy) ([]BootEntryInfo, []uint16, error) {
+	entries, err := collectBootEntries(c, pol)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lo.grep != "" {
+		entries = filterBootEntries(entries, lo.grep)
+	}
+
+	bootOrder, err := readListedBootOrder(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := sortBootEntries(entries, lo.sortBy, bootOrder); err != nil {
+		return nil, nil, err
+	}
+	return entries, bootOrder, nil
+}
+
+// readListedBootOrder returns the BootOrder to list.  As much of a
+// malformed BootOrder as possible is returned with a warning, and a
+// missing BootOrder is returned as an empty order.
+func readListedBootOrder(c efivario.Context) ([]uint16, error) {
+	raw, err := BootOptions.GetRawOrder(c)
+	if err != nil {
+		return nil, err
+	}
+	bootOrder, ok := decodeOrder(raw)
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s: odd size %d, ignoring the trailing byte\n", efivars.BootOrderName, len(raw))
+	}
+	return bootOrder, nil
+}
+
+// printListHeaders prints the boot manager state preceding the
+// entries in the listing.
+func (o *outputFlags) printListHeaders(c efivario.Context, p *printer.Printer, bootOrder []uint16) error {
+	// Report BootNext value.
+	_, bootNext, err := efivars.BootNext.Get(c)
+	if err != nil {
+		if !errors.Is(err, efivario.ErrNotFound) {
+			return err
+		}
+		// Ignore efivario.ErrNotFound errors.
+	} else {
+		p.PrintFieldValue("BootNext", o.bootIndex(bootNext))
+	}
+
+	// Report BootCurrent value.
+	_, bootCurrent, err := efivars.BootCurrent.Get(c)
+	if err != nil {
+		return err
+	}
+	p.PrintFieldValue("BootCurrent", o.bootIndex(bootCurrent))
+
+	// Report Timeout value.
+	timeout, ok, err := GetTimeout(c)
+	if err != nil {
+		return err
+	}
+	if ok {
+		p.PrintFieldValueWithNote("Timeout", timeout, "before the first entry of the BootOrder is booted")
+	}
+
+	// Report Secure Boot state.
+	if err := printSecureBoot(c, p); err != nil {
+		return err
+	}
+
+	if bootOrder != nil {
+		p.PrintFieldValue("BootOrder", o.bootIndices(bootOrder))
+	}
+	return nil
+}
+
+// printRawOrder prints the undecoded bytes of BootOrder.
+func printRawOrder(c efivario.Context, p *printer.Printer) error {
+	raw, err := BootOptions.GetRawOrder(c)
+	if err != nil || raw == nil {
+		return err
+	}
+	p.PrintFieldValue("BootOrder (raw)", HexDump(raw))
+	return nil
+}
+
+// truncateRunes shortens s to n runes, replacing the last one with
+// an ellipsis.  A limit of 0 leaves s untouched.
+func truncateRunes(s string, n uint) string {
+	if n == 0 || uint(utf8.RuneCountInString(s)) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// currentMarker returns the prefixes of the entry labels with
+// --since-boot: the marker for the entry the system was booted from
+// and padding of the same width for all other entries.  The current
+// index is only valid if ok is true.
+func (lo *listOptions) currentMarker(c efivario.Context) (index uint16, marker, padding string, ok bool, err error) {
+	if !lo.sinceBoot {
+		return 0, "", "", false, nil
+	}
+
+	_, index, err = efivars.BootCurrent.Get(c)
+	if err != nil {
+		if errors.Is(err, efivario.ErrNotFound) {
+			err = nil
+		}
+		return 0, "", "", false, err
+	}
+	marker = lo.marker + " "
+	return index, marker, strings.Repeat(" ", utf8.RuneCountInString(marker)), true, nil
+}
+
+// renderTable renders the boot manager state with the given boot
+// entries as printed by the list command.
+func (o *outputFlags) renderTable(c efivario.Context, lo *listOptions, entries []BootEntryInfo, bootOrder []uint16) (string, error) {
+	p := o.newPrinter()
+
+	if !lo.noHeaders {
+		if err := o.printListHeaders(c, p, bootOrder); err != nil {
+			return "", err
+		}
+		if lo.rawOrder {
+			if err := printRawOrder(c, p); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	current, marker, padding, marked, err := lo.currentMarker(c)
+	if err != nil {
+		return "", err
+	}
+
+	var rows [][]string
+	for _, e := range entries {
+		prefix := ""
+		switch {
+		case marked && e.Index == current:
+			prefix = marker
+		case lo.sinceBoot:
+			prefix = padding
+		}
+		label := prefix + o.entryLabel(o.bootEntryName(e.Index), e.Active)
+
+		text := e.Description
+		if !o.verbose {
+			text = truncateRunes(text, lo.truncate)
+		}
+
+		var description any = text
+		if !e.Active {
+			description = inactiveDescription(text)
+		}
+		if o.verbose {
+			description = describedEntry{description, e.Attributes}
+		}
+
+		if lo.fields != nil {
+			rows = append(rows, o.entryRow(p, prefix, e, description, lo.fields))
+			continue
+		}
+		p.PrintFieldValue(label, description)
+		if lo.showData && len(e.OptionalData) > 0 {
+			p.PrintFieldValue("OptionalData", lo.dataAs.Format(e.OptionalData))
+		}
+	}
+	renderColumns(p, rows)
+
+	return p.String(), nil
+}
+
+// entryRow returns the cells of the given fields of a boot entry
+// printed with --fields.
+func (o *outputFlags) entryRow(p *printer.Printer, prefix string, e BootEntryInfo, description any, fields []ListField) []string {
+	row := make([]string, len(fields))
+	for i, f := range fields {
+		switch f {
+		case FieldIndex:
+			row[i] = p.Format(o.bootIndex(e.Index))
+		case FieldActive:
+			row[i] = p.Format(e.Active)
+		case FieldLabel:
+			row[i] = p.Format(description)
+		case FieldDevicePath:
+			row[i] = printer.EscapeControl(e.DevicePath)
+		}
+	}
+	row[0] = prefix + row[0]
+	return row
+}
+
+// verbatimEntries returns the entries for the output formats writing
+// descriptions and device paths verbatim, templates and CSV.  Their
+// control characters are escaped when writing to a terminal, so that
+// they cannot control it.  JSON escapes control characters itself.
+func (o *outputFlags) verbatimEntries(entries []BootEntryInfo) []BootEntryInfo {
+	if o.toTerminal() {
+		return escapeEntries(entries)
+	}
+	return entries
+}
+
+func listE(args []string) (err error) {
+	fs, out := newOutputFlagSet("list")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	format := fs.String("format", "table", "output `format`: table, json, jsonl or csv")
+	asJSON := fs.Bool("json", false, "shorthand for --format=json")
+	count := fs.Bool("count", false, "only print the number of entries which would be listed")
+	tmpl := fs.String("template", "", "print the entries with the Go text/template `text`, executed against the same data as --json")
+	lo := newListOptions(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pol, err := lo.policy()
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+
+	if *count && (isFlagSet(fs, "format") || *asJSON) {
+		return errors.New("list: --count cannot be combined with --format or --json")
+	}
+
+	if lo.fields != nil && lo.showData {
+		return errors.New("list: --fields cannot be combined with --show-data")
+	}
+
+	if *tmpl != "" && (isFlagSet(fs, "format") || *asJSON || *count) {
+		return errors.New("list: --template cannot be combined with --format, --json or --count")
+	}
+
+	var t *template.Template
+	if *tmpl != "" {
+		if t, err = parseTemplate("list", *tmpl); err != nil {
+			return fmt.Errorf("list: %w", err)
+		}
+	}
+
+	if *asJSON {
+		*format = "json"
+	}
+
+	switch *format {
+	case "table", "json", "jsonl", "csv":
+	default:
+		return fmt.Errorf("list: unknown format %q", *format)
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	// JSON Lines are streamed as they are read unless they have
+	// to be sorted first.
+	if *format == "jsonl" && !isFlagSet(fs, "sort") {
+		if err := streamJSONLines(c, out.writer(), lo.grep, pol); err != nil {
+			return fmt.Errorf("list: %w", err)
+		}
+		return nil
+	}
+
+	entries, bootOrder, err := lo.load(c, pol)
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+
+	if *count {
+		_, _ = fmt.Fprintln(out.writer(), len(entries))
+		return nil
+	}
+
+	if t != nil {
+		return printTemplate(c, out.writer(), t, out.verbatimEntries(entries), bootOrder)
+	}
+
+	switch *format {
+	case "json":
+		return printJSON(c, out.writer(), entries, bootOrder)
+	case "jsonl":
+		return printJSONLines(out.writer(), entries)
+	case "csv":
+		return printCSV(out.writer(), out.verbatimEntries(entries), !lo.noHeaders, lo.fields, out.formatIndex)
+	}
+
+	s, err := out.renderTable(c, lo, entries, bootOrder)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(out.writer(), s)
+
+	return nil
+}
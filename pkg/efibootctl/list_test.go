@@ -0,0 +1,118 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// escapeDescription contains an escape sequence which would turn the
+// terminal red if printed verbatim.
+const escapeDescription = "Evil\x1b[31mRed"
+
+func TestListTableEscapesControlCharacters(t *testing.T) {
+	s := newTestStore(t, escapeDescription)
+
+	got, err := runCommand(t, s, listE, "--color", "always")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "\x1b[31mRed") {
+		t.Errorf("listing contains the raw escape sequence:\n%q", got)
+	}
+	if !strings.Contains(printer.StripANSI(got), `"Evil\x1b[31mRed"`) {
+		t.Errorf("listing lacks the escaped description:\n%q", got)
+	}
+}
+
+func TestListJSONKeepsDescriptionsRaw(t *testing.T) {
+	s := newTestStore(t, escapeDescription)
+
+	got, err := runCommand(t, s, listE, "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "\x1b") || strings.Contains(got, `\\x1b`) {
+		t.Errorf("JSON output is not escaped exactly once:\n%s", got)
+	}
+
+	var doc jsonDocument
+	if err := json.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Entries) != 1 || doc.Entries[0].Description != escapeDescription {
+		t.Errorf("entries = %+v, want the description %q", doc.Entries, escapeDescription)
+	}
+}
+
+func TestEscapeEntries(t *testing.T) {
+	entries := []BootEntryInfo{{Description: escapeDescription, DevicePath: "File(\u009b)"}}
+
+	got := escapeEntries(entries)
+	if got[0].Description != `Evil\x1b[31mRed` || got[0].DevicePath != `File(\u009b)` {
+		t.Errorf("escapeEntries() = %+v", got[0])
+	}
+	if entries[0].Description != escapeDescription {
+		t.Errorf("escapeEntries() modified its argument")
+	}
+}
+
+func TestListRejectsConflictingFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"fields and show-data", []string{"--fields", "index,label", "--show-data"}, "--fields cannot be combined with --show-data"},
+		{"count and json", []string{"--count", "--json"}, "--count cannot be combined"},
+		{"template and format", []string{"--template", "{{.}}", "--format", "csv"}, "--template cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCommand(t, newTestStore(t, "a"), listE, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("list %v error = %v, want %q", tt.args, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCSVIndices(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"hexadecimal", nil, "\n000A,"},
+		{"decimal", []string{"--decimal"}, "\n10,"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, strings.Split("abcdefghijk", "")...)
+
+			got, err := runCommand(t, s, listE, append(tt.args, "--format", "csv")...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("list --format csv %v = %q, want a row starting with %q", tt.args, got, tt.want[1:])
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+)
+
+// maxLoadOptionSize is the largest encoded load option written to
+// a load option variable.
+const maxLoadOptionSize = maxVariableSize
+
+// LoadOption is the writable counterpart of efitypes.LoadOption.
+//
+// The device path list is kept in its serialized form, so that
+// existing entries can be written back without having to
+// understand every device path node type they contain.
+type LoadOption struct {
+	// Attributes are the attributes for this load option entry.
+	Attributes efitypes.Attributes
+
+	// Description is the user readable description for the load
+	// option in utf16 encoding including the terminating Null
+	// character.
+	Description []byte
+
+	// FilePathList is the binary encoded list of device paths.
+	FilePathList []byte
+
+	// OptionalData is a binary data buffer that is passed to the
+	// loaded image.
+	OptionalData []byte
+}
+
+// DescriptionString returns the description as a Go string, see
+// decodeDescription.
+func (lo *LoadOption) DescriptionString() string {
+	return decodeDescription(lo.Description)
+}
+
+// decodeDescription decodes a null terminated little endian utf16
+// load option description.  Surrogate pairs are combined into a
+// single rune.  Unpaired surrogates have no UTF-8 encoding, they are
+// written as \uXXXX escapes instead, so that they can be told apart
+// from a U+FFFD replacement character in the description.
+func decodeDescription(b []byte) string {
+	codes := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			break
+		}
+		codes = append(codes, c)
+	}
+
+	var s strings.Builder
+	for i := 0; i < len(codes); i++ {
+		c := rune(codes[i])
+		switch {
+		case !utf16.IsSurrogate(c):
+			s.WriteRune(c)
+		case i+1 < len(codes) && utf16.DecodeRune(c, rune(codes[i+1])) != utf8.RuneError:
+			s.WriteRune(utf16.DecodeRune(c, rune(codes[i+1])))
+			i++
+		default:
+			fmt.Fprintf(&s, "\\u%04x", c)
+		}
+	}
+	return s.String()
+}
+
+// SetDescription replaces the description with the utf16 encoding
+// of the given string.
+func (lo *LoadOption) SetDescription(s string) {
+	lo.Description = encodeUTF16Z(s)
+}
+
+// MarshalBinary encodes the load option in the EFI_LOAD_OPTION
+// format expected by the firmware.
+func (lo *LoadOption) MarshalBinary() ([]byte, error) {
+	if len(lo.FilePathList) > math.MaxUint16 {
+		return nil, fmt.Errorf("LoadOption: file path list too long (%d bytes)", len(lo.FilePathList))
+	}
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, lo.Attributes)
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(len(lo.FilePathList)))
+	buf.Write(lo.Description)
+	buf.Write(lo.FilePathList)
+	buf.Write(lo.OptionalData)
+
+	if buf.Len() > maxLoadOptionSize {
+		return nil, fmt.Errorf("LoadOption: encoded size %d exceeds %d bytes", buf.Len(), maxLoadOptionSize)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a load option from the EFI_LOAD_OPTION
+// format.  The device path list and the optional data are kept as
+// they are, so that the load option can be written back unchanged.
+func (lo *LoadOption) UnmarshalBinary(data []byte) error {
+	if len(data) < 6 {
+		return errors.New("LoadOption: short header")
+	}
+	attrs := efitypes.Attributes(binary.LittleEndian.Uint32(data))
+	fplLength := int(binary.LittleEndian.Uint16(data[4:]))
+	data = data[6:]
+
+	descLength := -1
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 && data[i+1] == 0 {
+			descLength = i + 2
+			break
+		}
+	}
+	if descLength < 0 {
+		return errors.New("LoadOption: unterminated description")
+	}
+
+	if len(data)-descLength < fplLength {
+		return errors.New("LoadOption: short file path list")
+	}
+
+	lo.Attributes = attrs
+	lo.Description = append([]byte(nil), data[:descLength]...)
+	lo.FilePathList = append([]byte(nil), data[descLength:descLength+fplLength]...)
+	lo.OptionalData = append([]byte(nil), data[descLength+fplLength:]...)
+	return nil
+}
+
+// encodeUTF16Z encodes s as a Null terminated little endian utf16
+// byte sequence.
+func encodeUTF16Z(s string) []byte {
+	return append(encodeUTF16(s), 0, 0)
+}
+
+// encodeUTF16 encodes s as a little endian utf16 byte sequence
+// without terminator.
+func encodeUTF16(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+
+	out := make([]byte, len(codes)*2)
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(out[i*2:], c)
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"testing"
+)
+
+// utf16Bytes encodes the given utf16 code units little endian.
+func utf16Bytes(codes ...uint16) []byte {
+	out := make([]byte, 0, len(codes)*2)
+	for _, c := range codes {
+		out = append(out, byte(c), byte(c>>8))
+	}
+	return out
+}
+
+func TestDescriptionRoundTrip(t *testing.T) {
+	tests := []string{
+		"",
+		"Linux Boot Manager",
+		"Windows — über",
+		"\U0001F600 emoji \U0001F427",
+		"replacement � character",
+	}
+	for _, want := range tests {
+		var lo LoadOption
+		lo.SetDescription(want)
+		if got := lo.DescriptionString(); got != want {
+			t.Errorf("DescriptionString() after SetDescription(%q) = %q", want, got)
+		}
+	}
+}
+
+func TestDecodeDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"surrogate pair", utf16Bytes(0xd83d, 0xde00, 0), "\U0001F600"},
+		{"unpaired high surrogate", utf16Bytes('a', 0xd83d, 'b', 0), `a\ud83db`},
+		{"unpaired low surrogate", utf16Bytes('a', 0xde00, 0), `a\ude00`},
+		{"high surrogate at the end", utf16Bytes('a', 0xd83d), `a\ud83d`},
+		{"swapped pair", utf16Bytes(0xde00, 0xd83d, 0), `\ude00\ud83d`},
+		{"replacement character", utf16Bytes(0xfffd, 0), "�"},
+		{"text after the terminator", utf16Bytes('a', 0, 'b', 0), "a"},
+		{"odd length", append(utf16Bytes('a'), 'b'), "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeDescription(tt.in); got != tt.want {
+				t.Errorf("decodeDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// loadOptionFlagNames maps the LOAD_OPTION_* attribute bits to
+// their labels.
+var loadOptionFlagNames = []struct {
+	attr efitypes.Attributes
+	name string
+}{
+	{efitypes.ActiveAttribute, "ACTIVE"},
+	{efitypes.ForceReconnectAttribute, "FORCE_RECONNECT"},
+	{efitypes.HiddenAttribute, "HIDDEN"},
+}
+
+// LoadOptionFlags are the attributes of a load option.
+type LoadOptionFlags efitypes.Attributes
+
+// Names returns the labels of the set attributes followed by the
+// category, e.g. ["ACTIVE", "HIDDEN", "BOOT"].  Unknown bits are
+// returned in hexadecimal.
+func (f LoadOptionFlags) Names() (names []string) {
+	attrs := efitypes.Attributes(f)
+	for _, fn := range loadOptionFlagNames {
+		if attrs&fn.attr != 0 {
+			names = append(names, fn.name)
+			attrs &^= fn.attr
+		}
+	}
+
+	switch attrs & efitypes.CategoryAttribute {
+	case efitypes.CategoryBootAttribute:
+		names = append(names, "BOOT")
+	case efitypes.CategoryAppAttribute:
+		names = append(names, "APP")
+	default:
+		names = append(names, fmt.Sprintf("CATEGORY(%#x)", uint32(attrs&efitypes.CategoryAttribute)))
+	}
+	attrs &^= efitypes.CategoryAttribute
+
+	if attrs != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(attrs)))
+	}
+	return names
+}
+
+func (f LoadOptionFlags) String() string {
+	return strings.Join(f.Names(), ",")
+}
+
+// describedEntry prints the description of a boot entry followed
+// by its decoded attributes.
+type describedEntry struct {
+	description any
+	attrs       efitypes.Attributes
+}
+
+// inactiveDescription is the description of an inactive boot entry,
+// printed dimmed in the inactive color.
+type inactiveDescription string
+
+func (d inactiveDescription) PrettyPrint(p *printer.Printer) {
+	plain := printer.NewPrinter("", nil, true, true, true)
+	p.ColorPrint(plain.Format(string(d)), printer.InactiveColor)
+}
+
+func (d describedEntry) PrettyPrint(p *printer.Printer) {
+	p.Print(p.Format(d.description))
+	p.Print(" [")
+	p.ColorPrint(LoadOptionFlags(d.attrs).String(), printer.StructNameColor)
+	p.Print("]")
+}
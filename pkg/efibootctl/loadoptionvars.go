@@ -0,0 +1,345 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/0x5a17ed/itkit/itlib"
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+)
+
+// LoadOptionKind describes a family of load option variables
+// named <Prefix>#### together with the variable holding their
+// order, e.g. Driver#### and DriverOrder.
+type LoadOptionKind struct {
+	Prefix    string
+	OrderName string
+
+	nameRegexp *regexp.Regexp
+}
+
+func newLoadOptionKind(prefix, orderName string) *LoadOptionKind {
+	return &LoadOptionKind{
+		Prefix:     prefix,
+		OrderName:  orderName,
+		nameRegexp: regexp.MustCompile(`^` + prefix + `([\da-fA-F]{4})$`),
+	}
+}
+
+var (
+	// BootOptions are the Boot#### load options.
+	//
+	// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1346720>
+	BootOptions = newLoadOptionKind("Boot", efivars.BootOrderName)
+
+	// DriverOptions are the Driver#### load options.
+	//
+	// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1346720>
+	DriverOptions = newLoadOptionKind("Driver", "DriverOrder")
+
+	// SysPrepOptions are the SysPrep#### load options.
+	//
+	// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1346720>
+	SysPrepOptions = newLoadOptionKind("SysPrep", "SysPrepOrder")
+)
+
+var (
+	ErrNoFreeIndex        = errors.New("no free load option index left")
+	ErrLoadOptionNotFound = errors.New("load option does not exist")
+	ErrVerifyFailed       = errors.New("written value does not read back")
+)
+
+// VariableName returns the variable name of the load option with
+// the given index.
+func (k *LoadOptionKind) VariableName(index uint16) string {
+	return fmt.Sprintf("%s%04X", k.Prefix, index)
+}
+
+// parseVariableName returns the index encoded in the given
+// variable name if it belongs to this kind.
+func (k *LoadOptionKind) parseVariableName(name string) (uint16, bool) {
+	match := k.nameRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(match[1], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return uint16(value), true
+}
+
+// Indices returns the indices of all load option variables of
+// this kind currently present.
+func (k *LoadOptionKind) Indices(c efivario.Context) (out []uint16, err error) {
+	it, err := c.VariableNames()
+	if err != nil {
+		return nil, err
+	}
+	defer multierr.AppendInvoke(&err, multierr.Close(it))
+
+	itlib.Apply(it.Iter(), func(vn efivario.VariableNameItem) {
+		if vn.GUID != efivars.GlobalVariable {
+			return
+		}
+		if index, ok := k.parseVariableName(vn.Name); ok {
+			out = append(out, index)
+		}
+	})
+	return out, it.Err()
+}
+
+// Get reads the load option with the given index.
+func (k *LoadOptionKind) Get(c efivario.Context, index uint16) (*efitypes.LoadOption, error) {
+	_, data, err := readVariable(c, k.VariableName(index), efivars.GlobalVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	var lo efitypes.LoadOption
+	if _, err := lo.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("%s: %w", k.VariableName(index), err)
+	}
+	return &lo, nil
+}
+
+// GetOrder returns the current order of this kind's load options.
+// A missing order variable is reported as an empty order.
+func (k *LoadOptionKind) GetOrder(c efivario.Context) ([]uint16, error) {
+	data, err := k.GetRawOrder(c)
+	if err != nil {
+		return nil, err
+	}
+	order, ok := decodeOrder(data)
+	if !ok {
+		return nil, fmt.Errorf("%s: unexpected size %d", k.OrderName, len(data))
+	}
+	return order, nil
+}
+
+// GetRawOrder returns the undecoded order variable of this kind.
+// A missing order variable is reported as nil.
+func (k *LoadOptionKind) GetRawOrder(c efivario.Context) ([]byte, error) {
+	_, data, err := readVariable(c, k.OrderName, efivars.GlobalVariable)
+	if err != nil {
+		if errors.Is(err, efivario.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// decodeOrder decodes the indices of an order variable.  A trailing
+// odd byte is ignored, in which case ok is false.
+func decodeOrder(data []byte) (order []uint16, ok bool) {
+	if data == nil {
+		return nil, true
+	}
+
+	order = make([]uint16, len(data)/2)
+	for i := range order {
+		order[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return order, len(data)%2 == 0
+}
+
+// SetOrder replaces the order variable of this kind with the given
+// order.
+func (k *LoadOptionKind) SetOrder(c efivario.Context, order []uint16) error {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, order); err != nil {
+		return err
+	}
+	return c.Set(k.OrderName, efivars.GlobalVariable, defaultAttributes, buf.Bytes())
+}
+
+// Exists reports whether the load option with the given index
+// exists.
+func (k *LoadOptionKind) Exists(c efivario.Context, index uint16) (bool, error) {
+	_, _, err := readVariable(c, k.VariableName(index), efivars.GlobalVariable)
+	if err != nil {
+		if errors.Is(err, efivario.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// checkExists returns ErrLoadOptionNotFound unless every load
+// option with the given indices exists.
+func (k *LoadOptionKind) checkExists(c efivario.Context, indices ...uint16) error {
+	for _, index := range indices {
+		ok, err := k.Exists(c, index)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s: %w", k.VariableName(index), ErrLoadOptionNotFound)
+		}
+	}
+	return nil
+}
+
+// NextFreeIndex returns the lowest index not used by any load
+// option of this kind.
+func (k *LoadOptionKind) NextFreeIndex(c efivario.Context) (uint16, error) {
+	indices, err := k.Indices(c)
+	if err != nil {
+		return 0, err
+	}
+
+	used := make(map[uint16]bool, len(indices))
+	for _, index := range indices {
+		used[index] = true
+	}
+
+	for i := 0; i <= math.MaxUint16; i++ {
+		if !used[uint16(i)] {
+			return uint16(i), nil
+		}
+	}
+	return 0, ErrNoFreeIndex
+}
+
+// Write writes the given load option to the variable with the
+// given index.
+func (k *LoadOptionKind) Write(c efivario.Context, index uint16, lo *LoadOption) error {
+	data, err := lo.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return c.Set(k.VariableName(index), efivars.GlobalVariable, defaultAttributes, data)
+}
+
+// Read reads the load option with the given index in its writable
+// form.
+func (k *LoadOptionKind) Read(c efivario.Context, index uint16) (*LoadOption, error) {
+	_, data, err := readVariable(c, k.VariableName(index), efivars.GlobalVariable)
+	if err != nil {
+		if errors.Is(err, efivario.ErrNotFound) {
+			return nil, fmt.Errorf("%s: %w", k.VariableName(index), ErrLoadOptionNotFound)
+		}
+		return nil, err
+	}
+
+	lo := &LoadOption{}
+	if err := lo.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", k.VariableName(index), err)
+	}
+	return lo, nil
+}
+
+// Update reads the load option with the given index, passes it to
+// fn for modification and writes it back.  The variable is read
+// again afterwards to verify that the firmware stored the value
+// as written.
+func (k *LoadOptionKind) Update(c efivario.Context, index uint16, fn func(lo *LoadOption) error) error {
+	lo, err := k.Read(c, index)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(lo); err != nil {
+		return fmt.Errorf("%s: %w", k.VariableName(index), err)
+	}
+
+	want, err := lo.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("%s: %w", k.VariableName(index), err)
+	}
+
+	if err := c.Set(k.VariableName(index), efivars.GlobalVariable, defaultAttributes, want); err != nil {
+		return err
+	}
+
+	_, got, err := readVariable(c, k.VariableName(index), efivars.GlobalVariable)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%s: %w", k.VariableName(index), ErrVerifyFailed)
+	}
+	return nil
+}
+
+// Create writes the given load option to the next free index and
+// appends it to the order.
+func (k *LoadOptionKind) Create(c efivario.Context, lo *LoadOption) (uint16, error) {
+	index, err := k.NextFreeIndex(c)
+	if err != nil {
+		return 0, err
+	}
+
+	order, err := k.GetOrder(c)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := k.Write(c, index, lo); err != nil {
+		return 0, err
+	}
+
+	if err := k.SetOrder(c, append(order, index)); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// Delete removes the load options with the given indices and drops
+// them from the order.  The order is only rewritten once all
+// variables have been removed.
+func (k *LoadOptionKind) Delete(c efivario.Context, indices ...uint16) error {
+	if err := k.checkExists(c, indices...); err != nil {
+		return err
+	}
+
+	order, err := k.GetOrder(c)
+	if err != nil {
+		return err
+	}
+
+	deleted := make(map[uint16]bool, len(indices))
+	for _, index := range indices {
+		if err := c.Delete(k.VariableName(index), efivars.GlobalVariable); err != nil {
+			return err
+		}
+		deleted[index] = true
+	}
+
+	newOrder := make([]uint16, 0, len(order))
+	for _, index := range order {
+		if !deleted[index] {
+			newOrder = append(newOrder, index)
+		}
+	}
+
+	if len(newOrder) == len(order) {
+		return nil
+	}
+	return k.SetOrder(c, newOrder)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+)
+
+func TestLargeLoadOption(t *testing.T) {
+	data := bytes.Repeat([]byte{0xa5}, 2500)
+
+	tests := []struct {
+		name  string
+		op    func(c VarStore) (uint16, error)
+		check func(t *testing.T, lo *LoadOption)
+	}{
+		{
+			name: "set-args",
+			op:   func(c VarStore) (uint16, error) { return 0, SetOptionalData(c, 0, data) },
+		},
+		{
+			name: "rename",
+			op:   func(c VarStore) (uint16, error) { return 0, Rename(c, 0, "renamed") },
+			check: func(t *testing.T, lo *LoadOption) {
+				if got := lo.DescriptionString(); got != "renamed" {
+					t.Errorf("description = %q, want %q", got, "renamed")
+				}
+			},
+		},
+		{
+			name: "deactivate",
+			op:   func(c VarStore) (uint16, error) { return 0, Deactivate(c, 0) },
+			check: func(t *testing.T, lo *LoadOption) {
+				if lo.Attributes&efitypes.ActiveAttribute != 0 {
+					t.Error("entry is still active")
+				}
+			},
+		},
+		{
+			name: "hide",
+			op:   func(c VarStore) (uint16, error) { return 0, Hide(c, 0) },
+			check: func(t *testing.T, lo *LoadOption) {
+				if lo.Attributes&efitypes.HiddenAttribute == 0 {
+					t.Error("entry is not hidden")
+				}
+			},
+		},
+		{
+			name: "clone",
+			op:   func(c VarStore) (uint16, error) { return CloneBootEntry(c, 0, "copy", "") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, "a")
+			if err := SetOptionalData(s, 0, data); err != nil {
+				t.Fatalf("SetOptionalData() = %v", err)
+			}
+
+			index, err := tt.op(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lo, err := BootOptions.Read(s, index)
+			if err != nil {
+				t.Fatalf("Read() = %v", err)
+			}
+			if !bytes.Equal(lo.OptionalData, data) {
+				t.Errorf("optional data of %d bytes does not match the %d bytes written", len(lo.OptionalData), len(data))
+			}
+			if _, err := BootOptions.Get(s, index); err != nil {
+				t.Errorf("Get() = %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, lo)
+			}
+		})
+	}
+}
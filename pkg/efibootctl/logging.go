@@ -0,0 +1,115 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"log"
+	"os"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// logLevel selects the variable accesses logged by a loggingContext.
+type logLevel int
+
+const (
+	// logSilent logs nothing.
+	logSilent logLevel = iota
+
+	// logWrites logs writes and deletions of variables.
+	logWrites
+
+	// logAll additionally logs every read.
+	logAll
+)
+
+// loggingContext is an efivario.Context logging the accesses to the
+// wrapped context to standard error.  It uses log rather than
+// log/slog, which only exists since Go 1.21 while go.mod still
+// targets Go 1.18.
+type loggingContext struct {
+	efivario.Context
+
+	level logLevel
+	log   *log.Logger
+}
+
+var _ efivario.Context = &loggingContext{}
+
+// newLoggingContext wraps c to log its accesses at the given level.
+// c is returned as is if nothing is to be logged.
+func newLoggingContext(c efivario.Context, level logLevel) efivario.Context {
+	if level == logSilent {
+		return c
+	}
+	return &loggingContext{Context: c, level: level, log: log.New(os.Stderr, "debug: ", 0)}
+}
+
+func (c *loggingContext) GetSizeHint(name string, guid efiguid.GUID) (int64, error) {
+	n, err := c.Context.GetSizeHint(name, guid)
+	if c.level >= logAll {
+		if err != nil {
+			c.log.Printf("size %s: %v", dryRunKey(name, guid), err)
+		} else {
+			c.log.Printf("size %s: %d bytes", dryRunKey(name, guid), n)
+		}
+	}
+	return n, err
+}
+
+func (c *loggingContext) Get(name string, guid efiguid.GUID, out []byte) (efivario.Attributes, int, error) {
+	attrs, n, err := c.Context.Get(name, guid, out)
+	if c.level >= logAll {
+		if err != nil {
+			c.log.Printf("get %s into %d bytes: %v", dryRunKey(name, guid), len(out), err)
+		} else {
+			c.log.Printf("get %s: %d bytes, attributes %s", dryRunKey(name, guid), n, formatAttributes(attrs))
+		}
+	}
+	return attrs, n, err
+}
+
+func (c *loggingContext) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	err := c.Context.Set(name, guid, attrs, value)
+	if err != nil {
+		c.log.Printf("set %s: %d bytes, attributes %s: %v", dryRunKey(name, guid), len(value), formatAttributes(attrs), err)
+	} else {
+		c.log.Printf("set %s: %d bytes, attributes %s", dryRunKey(name, guid), len(value), formatAttributes(attrs))
+	}
+	return err
+}
+
+func (c *loggingContext) Delete(name string, guid efiguid.GUID) error {
+	err := c.Context.Delete(name, guid)
+	if err != nil {
+		c.log.Printf("delete %s: %v", dryRunKey(name, guid), err)
+	} else {
+		c.log.Printf("delete %s", dryRunKey(name, guid))
+	}
+	return err
+}
+
+func (c *loggingContext) VariableNames() (efivario.VariableNameIterator, error) {
+	it, err := c.Context.VariableNames()
+	if c.level >= logAll {
+		if err != nil {
+			c.log.Printf("list variables: %v", err)
+		} else {
+			c.log.Printf("list variables")
+		}
+	}
+	return it, err
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package efibootctl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+const (
+	procMountInfo = "/proc/self/mountinfo"
+	sysDevBlock   = "/sys/dev/block"
+)
+
+// unescapeMountInfo decodes the octal escapes used for spaces and
+// other special characters in mount points in mountinfo.
+func unescapeMountInfo(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// findMountDevice returns the major:minor device number of the
+// file system mounted at the given mount point.
+func findMountDevice(mountPoint string) (device string, err error) {
+	f, err := os.Open(procMountInfo)
+	if err != nil {
+		return "", err
+	}
+	defer multierr.AppendInvoke(&err, multierr.Close(f))
+
+	// Later mounts hide earlier ones at the same mount point.
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		if unescapeMountInfo(fields[4]) == mountPoint {
+			device = fields[2]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if device == "" {
+		return "", fmt.Errorf("%s: not a mount point", mountPoint)
+	}
+	return device, nil
+}
+
+func ResolveMountPoint(path string) (string, uint32, error) {
+	mountPoint, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", 0, err
+	}
+	if mountPoint, err = filepath.Abs(mountPoint); err != nil {
+		return "", 0, err
+	}
+
+	device, err := findMountDevice(mountPoint)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The sysfs node of a partition is located below the node of
+	// the disk holding it.
+	partPath, err := filepath.EvalSymlinks(filepath.Join(sysDevBlock, device))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", 0, fmt.Errorf("%s: not backed by a block device", mountPoint)
+		}
+		return "", 0, fmt.Errorf("%s: %w", mountPoint, err)
+	}
+	number, err := readSysfsUint(filepath.Join(partPath, "partition"))
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %s is not a partition", mountPoint, filepath.Base(partPath))
+	}
+	return filepath.Join("/dev", filepath.Base(filepath.Dir(partPath))), uint32(number), nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"go.uber.org/multierr"
+)
+
+// MoveInOrder returns a copy of order with index moved to the given
+// zero based position.  Positions past the end move the index to
+// the end.  The index has to be part of order.
+func MoveInOrder(order []uint16, index uint16, pos int) ([]uint16, error) {
+	from := positionOf(order, index)
+	if from < 0 {
+		return nil, fmt.Errorf("%s: not part of the BootOrder", bootEntryName(index))
+	}
+
+	out := make([]uint16, 0, len(order))
+	out = append(out, order[:from]...)
+	out = append(out, order[from+1:]...)
+
+	switch {
+	case pos < 0:
+		pos = 0
+	case pos > len(out):
+		pos = len(out)
+	}
+
+	out = append(out[:pos], append([]uint16{index}, out[pos:]...)...)
+	return out, nil
+}
+
+// positionOf returns the position of index in order or -1.
+func positionOf(order []uint16, index uint16) int {
+	for i, v := range order {
+		if v == index {
+			return i
+		}
+	}
+	return -1
+}
+
+func moveE(args []string) (err error) {
+	fs, out := newWriteFlagSet("move")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	toTop := fs.Bool("to-top", false, "move the entry to the start of the BootOrder")
+	toBottom := fs.Bool("to-bottom", false, "move the entry to the end of the BootOrder")
+	position := fs.Int("position", 0, "move the entry to the given one based `position`")
+	up := fs.Bool("up", false, "move the entry one position towards the start")
+	down := fs.Bool("down", false, "move the entry one position towards the end")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("move: exactly one boot entry index is required")
+	}
+
+	index, err := out.parseIndex(positional[0])
+	if err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	selected := 0
+	for _, set := range []bool{*toTop, *toBottom, isFlagSet(fs, "position"), *up, *down} {
+		if set {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return errors.New("move: exactly one of --to-top, --to-bottom, --position, --up or --down is required")
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	oldOrder, err := GetBootOrder(c)
+	if err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	from := positionOf(oldOrder, index)
+	if from < 0 {
+		return fmt.Errorf("move: %s: not part of the BootOrder", bootEntryName(index))
+	}
+
+	if isFlagSet(fs, "position") && (*position < 1 || *position > len(oldOrder)) {
+		return fmt.Errorf("move: --position %d is out of range, the BootOrder has positions 1 to %d", *position, len(oldOrder))
+	}
+
+	var to int
+	switch {
+	case *toTop:
+		to = 0
+	case *toBottom:
+		to = len(oldOrder) - 1
+	case *up:
+		to = from - 1
+	case *down:
+		to = from + 1
+	default:
+		to = *position - 1
+	}
+
+	// Moving the first entry up or the last one down leaves the
+	// BootOrder as it is, which is not worth a write.
+	if to == from || to < 0 || to >= len(oldOrder) {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s is already at that position, the BootOrder is unchanged\n", out.bootEntryName(index))
+		return nil
+	}
+
+	newOrder, err := MoveInOrder(oldOrder, index, to)
+	if err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	if err := SetBootOrder(c, newOrder); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	p := out.newPrinter()
+	p.PrintFieldValue("Before", out.bootIndices(oldOrder))
+	p.PrintFieldValue("After", out.bootIndices(newOrder))
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// countingStore is a MemoryVarStore counting the writes it receives.
+type countingStore struct {
+	*MemoryVarStore
+	writes int
+}
+
+func (s *countingStore) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	s.writes++
+	return s.MemoryVarStore.Set(name, guid, attrs, value)
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantErr   string
+		wantOrder []uint16
+		wantWrite bool
+	}{
+		{name: "up", args: []string{"--up", "0001"}, wantOrder: []uint16{1, 0, 2}, wantWrite: true},
+		{name: "down", args: []string{"--down", "0001"}, wantOrder: []uint16{0, 2, 1}, wantWrite: true},
+		{name: "up on the first entry", args: []string{"--up", "0000"}, wantOrder: []uint16{0, 1, 2}},
+		{name: "down on the last entry", args: []string{"--down", "0002"}, wantOrder: []uint16{0, 1, 2}},
+		{name: "to top on the first entry", args: []string{"--to-top", "0000"}, wantOrder: []uint16{0, 1, 2}},
+		{name: "position", args: []string{"--position", "3", "0000"}, wantOrder: []uint16{1, 2, 0}, wantWrite: true},
+		{name: "position 0", args: []string{"--position", "0", "0000"}, wantErr: "move: --position 0 is out of range", wantOrder: []uint16{0, 1, 2}},
+		{name: "position past the end", args: []string{"--position", "4", "0000"}, wantErr: "move: --position 4 is out of range", wantOrder: []uint16{0, 1, 2}},
+		{name: "no movement", args: []string{"0000"}, wantErr: "move: exactly one of", wantOrder: []uint16{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &countingStore{MemoryVarStore: newTestStore(t, "a", "b", "c")}
+
+			var err error
+			captureStderr(t, func() {
+				_, err = runCommand(t, s, moveE, tt.args...)
+			})
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Fatalf("move %v = %v, want %q", tt.args, err, tt.wantErr)
+			}
+
+			order, err := GetBootOrder(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("BootOrder = %v, want %v", order, tt.wantOrder)
+			}
+			if (s.writes != 0) != tt.wantWrite {
+				t.Errorf("%d writes, want a write %v", s.writes, tt.wantWrite)
+			}
+		})
+	}
+}
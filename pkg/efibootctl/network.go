@@ -0,0 +1,57 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"go.uber.org/multierr"
+)
+
+func createNetworkE(args []string) (err error) {
+	fs, out := newWriteFlagSet("create-network")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	mac := fs.String("mac", "", "MAC `address` of the network interface to boot from")
+	label := fs.String("label", "", "`description` of the new entry")
+	inactive := fs.Bool("inactive", false, "create the entry without the active attribute")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case *mac == "":
+		return errors.New("create-network: --mac is required")
+	case *label == "":
+		return errors.New("create-network: --label is required")
+	}
+
+	addr, err := net.ParseMAC(*mac)
+	if err != nil {
+		return fmt.Errorf("create-network: %w", err)
+	}
+
+	lo := &LoadOption{
+		FilePathList: new(DevicePathBuilder).MACAddress(addr).IPv4().End(),
+	}
+	lo.SetDescription(*label)
+	if !*inactive {
+		lo.Attributes |= efitypes.ActiveAttribute
+	}
+
+	return out.createLoadOption("create-network", BootOptions, lo)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+)
+
+func nextE(args []string) (err error) {
+	fs, out := newWriteFlagSet("next")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	clear := fs.Bool("clear", false, "remove BootNext instead of setting it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var index uint16
+	switch {
+	case *clear && fs.NArg() != 0:
+		return errors.New("next: --clear does not take a boot entry index")
+	case !*clear && fs.NArg() != 1:
+		return errors.New("next: exactly one boot entry index is required")
+	case !*clear:
+		if index, err = out.parseIndex(fs.Arg(0)); err != nil {
+			return fmt.Errorf("next: %w", err)
+		}
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	if *clear {
+		err = ClearBootNext(c)
+	} else {
+		err = SetBootNext(c, index)
+	}
+	if err != nil {
+		return fmt.Errorf("next: %w", err)
+	}
+
+	if !*clear {
+		warnBootNextSupport(c)
+	}
+	return nil
+}
+
+// warnBootNextSupport warns on standard error if the firmware might
+// ignore the BootNext just written, see bootNextWarning.  The warning
+// only depends on what the firmware advertises, so it is shown for
+// dry runs as well.
+func warnBootNextSupport(c efivario.Context) {
+	if warning := bootNextWarning(c); warning != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+}
+
+// bootNextWarning returns a warning if the firmware might ignore
+// BootNext, or an empty string.  BootNext has no capability bit of
+// its own, so firmware which does not advertise its boot manager
+// capabilities at all is taken as a hint that its boot manager is
+// incomplete.
+func bootNextWarning(c efivario.Context) string {
+	_, ok, err := BootOptionSupport(c)
+	switch {
+	case err != nil:
+		return err.Error()
+	case !ok:
+		return "the firmware does not advertise its boot manager capabilities, it might ignore BootNext"
+	}
+	return ""
+}
+
+func deleteNextE(args []string) error {
+	return nextE(append([]string{"--clear"}, args...))
+}
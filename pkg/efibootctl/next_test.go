@@ -0,0 +1,117 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+func TestBootNextWarning(t *testing.T) {
+	tests := []struct {
+		name    string
+		support []byte
+		want    bool
+	}{
+		{"no BootOptionSupport", nil, true},
+		{"without the Key capability", []byte{0, 0, 0, 0}, false},
+		{"with the Key capability", []byte{1, 0, 0, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, "a")
+			if tt.support != nil {
+				if err := s.Set(BootOptionSupportName, efivars.GlobalVariable, defaultAttributes, tt.support); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := bootNextWarning(s); (got != "") != tt.want {
+				t.Errorf("bootNextWarning() = %q, want a warning %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextValidatesIndexBeforeWriting(t *testing.T) {
+	s := newTestStore(t, "a")
+
+	if _, err := runCommand(t, s, nextE, "0007"); err == nil {
+		t.Fatal("next of a missing entry succeeded")
+	}
+	if _, _, err := efivars.BootNext.Get(s); err == nil {
+		t.Error("BootNext was written for a missing entry")
+	}
+
+	if _, err := runCommand(t, s, nextE, "0000"); err != nil {
+		t.Fatal(err)
+	}
+	if _, next, err := efivars.BootNext.Get(s); err != nil || next != 0 {
+		t.Errorf("BootNext = %d, %v, want 0", next, err)
+	}
+}
+
+// captureStderr runs fn with standard error redirected to a file and
+// returns what fn wrote to it.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	saved := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = saved }()
+	fn()
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestNextWarnsAboutBootNextSupport(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"next", []string{"0000"}},
+		{"next dry run", []string{"--dry-run", "0000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, "a")
+
+			var err error
+			stderr := captureStderr(t, func() {
+				_, err = runCommand(t, s, nextE, tt.args...)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(stderr, "warning: the firmware does not advertise") {
+				t.Errorf("standard error = %q, want the BootNext warning", stderr)
+			}
+		})
+	}
+}
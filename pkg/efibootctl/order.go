@@ -0,0 +1,115 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+func orderE(args []string) (err error) {
+	fs, out := newWriteFlagSet("order")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return errors.New("order: a comma separated list of boot entry indices is required")
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	existing, err := BootEntryIndices(c)
+	if err != nil {
+		return fmt.Errorf("order: %w", err)
+	}
+
+	newOrder, err := parseBootOrder(strings.Join(fs.Args(), ","), existing, out.indexBase())
+	if err != nil {
+		return fmt.Errorf("order: %w", err)
+	}
+
+	for _, index := range MissingFromOrder(newOrder, existing) {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s is not part of the new BootOrder\n", bootEntryName(index))
+	}
+
+	oldOrder, err := GetBootOrder(c)
+	if err != nil {
+		return fmt.Errorf("order: %w", err)
+	}
+
+	if err := SetBootOrder(c, newOrder); err != nil {
+		return fmt.Errorf("order: %w", err)
+	}
+
+	p := out.newPrinter()
+	p.PrintFieldValue("Before", out.bootIndices(oldOrder))
+	p.PrintFieldValue("After", out.bootIndices(newOrder))
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
+
+// formatOrder returns the given order as a comma separated list of
+// indices in the given base, as accepted by the order command.
+func formatOrder(order []uint16, base int) string {
+	parts := make([]string, len(order))
+	for i, index := range order {
+		if base == 10 {
+			parts[i] = strconv.Itoa(int(index))
+		} else {
+			parts[i] = fmt.Sprintf("%04X", index)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func getOrderE(args []string) (err error) {
+	fs, out := newOutputFlagSet("get-order")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	asJSON := fs.Bool("json", false, "print the BootOrder as a JSON array")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("get-order: no arguments expected")
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	order, err := GetBootOrder(c)
+	if err != nil {
+		return fmt.Errorf("get-order: %w", err)
+	}
+
+	if *asJSON {
+		if order == nil {
+			order = []uint16{}
+		}
+		return json.NewEncoder(out.writer()).Encode(out.bootIndices(order))
+	}
+	_, _ = fmt.Fprintln(out.writer(), formatOrder(order, out.indexBase()))
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+func TestGetOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		descriptions []string
+		args         []string
+		want         string
+	}{
+		{"hex", []string{"a", "b"}, nil, "0000,0001"},
+		{"decimal", []string{"a", "b"}, []string{"--decimal"}, "0,1"},
+		{"json", []string{"a", "b"}, []string{"--json"}, `["0000","0001"]`},
+		{"json decimal", []string{"a", "b"}, []string{"--json", "--decimal"}, `[0,1]`},
+		{"empty", nil, nil, ""},
+		{"empty json", nil, []string{"--json"}, `[]`},
+		{"empty json decimal", nil, []string{"--json", "--decimal"}, `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, tt.descriptions...)
+
+			got, err := runCommand(t, s, getOrderE, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got = strings.TrimSuffix(got, "\n"); got != tt.want {
+				t.Errorf("get-order %v = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalIndicesAreNotGrouped(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(args []string) error
+		args []string
+		want string
+	}{
+		{"list", listE, []string{"--decimal"}, "1000"},
+		{"get-order", getOrderE, []string{"--decimal"}, "1000,0"},
+		{"get-order json", getOrderE, []string{"--decimal", "--json"}, "[1000,0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, "a")
+			if err := s.Set(efivars.BootOrderName, efivars.GlobalVariable, defaultAttributes, []byte{0xe8, 0x03, 0, 0}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := runCommand(t, s, tt.run, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, tt.want) || strings.Contains(got, "1,000") {
+				t.Errorf("%s %v = %q, want %q without grouping", tt.name, tt.args, got, tt.want)
+			}
+		})
+	}
+}
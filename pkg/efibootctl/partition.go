@@ -0,0 +1,59 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+)
+
+// Partition describes the location of a partition on a disk as
+// required by a hard drive media device path node.
+type Partition struct {
+	// Number is the entry in the partition table, starting
+	// with entry 1.
+	Number uint32
+
+	// StartLBA is the first logical block of the partition.
+	StartLBA uint64
+
+	// SizeLBA is the size of the partition in logical blocks.
+	SizeLBA uint64
+
+	// Format describes the format of the partition table.
+	Format efidevicepath.PartitionFormat
+
+	// SignatureType describes the content of Signature.
+	SignatureType efidevicepath.SignatureType
+
+	// Signature identifies the partition, see
+	// efidevicepath.HardDriveMediaDevicePath for details.
+	Signature [16]byte
+}
+
+// LookupPartitionFn returns the location of the partition with the
+// given number on the given disk device.
+type LookupPartitionFn func(disk string, number uint32) (*Partition, error)
+
+// ResolveMountPointFn returns the disk device and the number of the
+// partition holding the file system mounted at the given path.
+type ResolveMountPointFn func(path string) (disk string, number uint32, err error)
+
+// resolveMountPoint and lookupPartition locate the partition a new
+// load option points at.  They are variables, so that the commands
+// can be run against fake disks instead of the disks of the host.
+var (
+	resolveMountPoint ResolveMountPointFn = ResolveMountPoint
+	lookupPartition   LookupPartitionFn   = LookupPartition
+)
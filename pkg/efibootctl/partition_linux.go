@@ -0,0 +1,173 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package efibootctl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+	"go.uber.org/multierr"
+)
+
+const (
+	sysClassBlock  = "/sys/class/block"
+	devDiskPartUID = "/dev/disk/by-partuuid"
+
+	// sysfs reports partition offsets and sizes in 512 byte
+	// sectors regardless of the actual block size.
+	sysfsSectorSize = 512
+)
+
+func readSysfsUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// findPartitionName returns the kernel name of the partition with
+// the given number on the given disk.
+func findPartitionName(disk string, number uint32) (string, error) {
+	entries, err := os.ReadDir(filepath.Join(sysClassBlock, disk))
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		n, err := readSysfsUint(filepath.Join(sysClassBlock, disk, entry.Name(), "partition"))
+		if err != nil {
+			continue
+		}
+		if n == uint64(number) {
+			return entry.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("%s: partition %d not found", disk, number)
+}
+
+// readPartitionSignature resolves the PARTUUID of the given
+// partition through the udev maintained symlinks.
+func readPartitionSignature(p *Partition, partName string) error {
+	entries, err := os.ReadDir(devDiskPartUID)
+	if err != nil {
+		return fmt.Errorf("partuuid: %w", err)
+	}
+
+	for _, entry := range entries {
+		target, err := filepath.EvalSymlinks(filepath.Join(devDiskPartUID, entry.Name()))
+		if err != nil || filepath.Base(target) != partName {
+			continue
+		}
+
+		uuid := entry.Name()
+		switch len(uuid) {
+		case 36:
+			// GPT partitions are identified by their unique
+			// partition GUID.
+			g, err := efiguid.FromString(uuid)
+			if err != nil {
+				return fmt.Errorf("partuuid: %w", err)
+			}
+			p.Format = efidevicepath.GUIDPartitionFormat
+			p.SignatureType = efidevicepath.GUIDSignatureType
+			p.Signature = g
+		case 11:
+			// MBR partitions are identified by the disk
+			// signature followed by the partition number.
+			sig, err := strconv.ParseUint(uuid[:8], 16, 32)
+			if err != nil {
+				return fmt.Errorf("partuuid: %w", err)
+			}
+			p.Format = efidevicepath.PCATPartitionFormat
+			p.SignatureType = efidevicepath.PCATSignatureType
+			binary.LittleEndian.PutUint32(p.Signature[:], uint32(sig))
+		default:
+			return fmt.Errorf("partuuid: unrecognized format %q", uuid)
+		}
+		return nil
+	}
+	return fmt.Errorf("partuuid: %s not found", partName)
+}
+
+// readDiskGPTPartition reads the partition with the given number
+// from the GUID partition table of the given disk device.
+func readDiskGPTPartition(disk string, blockSize uint64, number uint32) (p *Partition, err error) {
+	f, err := os.Open(disk)
+	if err != nil {
+		return nil, err
+	}
+	defer multierr.AppendInvoke(&err, multierr.Close(f))
+
+	return ReadGPTPartition(f, blockSize, number)
+}
+
+func LookupPartition(disk string, number uint32) (*Partition, error) {
+	resolved, err := filepath.EvalSymlinks(disk)
+	if err != nil {
+		return nil, err
+	}
+	diskName := filepath.Base(resolved)
+
+	partName, err := findPartitionName(diskName, number)
+	if err != nil {
+		return nil, err
+	}
+
+	blockSize, err := readSysfsUint(filepath.Join(sysClassBlock, diskName, "queue", "logical_block_size"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer the partition table itself over the information
+	// exported by the kernel, which lacks the partition GUID for
+	// disks not known to udev.
+	p, err := readDiskGPTPartition(resolved, blockSize, number)
+	if !errors.Is(err, ErrNoGPT) {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", diskName, err)
+		}
+		return p, nil
+	}
+
+	start, err := readSysfsUint(filepath.Join(sysClassBlock, partName, "start"))
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := readSysfsUint(filepath.Join(sysClassBlock, partName, "size"))
+	if err != nil {
+		return nil, err
+	}
+
+	p = &Partition{
+		Number:   number,
+		StartLBA: start * sysfsSectorSize / blockSize,
+		SizeLBA:  size * sysfsSectorSize / blockSize,
+	}
+	if err := readPartitionSignature(p, partName); err != nil {
+		return nil, fmt.Errorf("%s: %w", partName, err)
+	}
+	return p, nil
+}
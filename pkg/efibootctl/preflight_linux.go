@@ -0,0 +1,54 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// sysFirmwareEFI only exists if the kernel was booted through UEFI.
+const sysFirmwareEFI = "/sys/firmware/efi"
+
+// CheckEFISystem returns ErrNotEFISystem if the EFI variables are
+// not accessible through efivarfs.
+func CheckEFISystem() error {
+	dir := os.Getenv("EFIVARFS_PATH")
+	if dir == "" {
+		dir = efivario.DefaultEfiPath
+	}
+
+	fi, err := os.Stat(dir)
+	switch {
+	case err == nil && fi.IsDir():
+		return nil
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		// Let the actual variable access report other errors.
+		return nil
+	}
+
+	if dir == efivario.DefaultEfiPath {
+		if _, err := os.Stat(sysFirmwareEFI); err == nil {
+			return fmt.Errorf("%w: efivarfs is not mounted at %s", ErrNotEFISystem, dir)
+		}
+	}
+	return ErrNotEFISystem
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package efibootctl
+
+import (
+	"errors"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+	"golang.org/x/sys/windows"
+)
+
+// CheckEFISystem returns ErrNotEFISystem on legacy BIOS systems,
+// where querying any firmware variable fails with
+// ERROR_INVALID_FUNCTION.
+func CheckEFISystem() (err error) {
+	c := efivario.NewDefaultContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	_, _, getErr := c.Get("", efiguid.GUID{}, nil)
+	if errors.Is(getErr, windows.ERROR_INVALID_FUNCTION) {
+		return ErrNotEFISystem
+	}
+	return nil
+}
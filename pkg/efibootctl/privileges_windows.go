@@ -17,9 +17,31 @@
 package efibootctl
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+
 	"github.com/Microsoft/go-winio"
 )
 
+// systemEnvironmentPrivilege is required to read and write EFI
+// variables and is only granted to elevated processes.
+const systemEnvironmentPrivilege = "SeSystemEnvironmentPrivilege"
+
+// ErrNotElevated is returned if the process does not hold the
+// privilege required to access EFI variables.
+var ErrNotElevated = fmt.Errorf(
+	"%w: the %s privilege is not held, run efibootctl from an elevated command prompt (\"Run as administrator\")",
+	fs.ErrPermission, systemEnvironmentPrivilege)
+
 func RunWithPrivileges(cb func() error) error {
-	return winio.RunWithPrivilege("SeSystemEnvironmentPrivilege", cb)
+	err := winio.RunWithPrivilege(systemEnvironmentPrivilege, cb)
+
+	// winio fails to enable privileges the process token does not
+	// hold, which is the case for processes which are not elevated.
+	var pe *winio.PrivilegeError
+	if errors.As(err, &pe) {
+		return ErrNotElevated
+	}
+	return err
 }
@@ -0,0 +1,49 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package efibootctl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestRunWithPrivilegesNotElevated needs to run from a command
+// prompt which is not elevated.
+func TestRunWithPrivilegesNotElevated(t *testing.T) {
+	called := false
+	err := RunWithPrivileges(func() error {
+		called = true
+		return nil
+	})
+	if err == nil {
+		t.Skip("the process is elevated")
+	}
+
+	if called {
+		t.Error("the callback ran without the privilege")
+	}
+	if !errors.Is(err, ErrNotElevated) {
+		t.Fatalf("RunWithPrivileges() = %v, want ErrNotElevated", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "Run as administrator") {
+		t.Errorf("error = %q, want an instruction to run elevated", msg)
+	}
+	if code := ExitCode(err); code != ExitPermissionDenied {
+		t.Errorf("ExitCode() = %d, want %d", code, ExitPermissionDenied)
+	}
+}
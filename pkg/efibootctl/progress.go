@@ -0,0 +1,65 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// progress reports the progress of reading many variables on
+// standard error, updating a single line in place.
+type progress struct {
+	label string
+	width int
+}
+
+// newProgress returns a progress indicator with the given label.
+// It returns nil, which reports nothing, if standard error is not
+// a terminal or quiet is set.
+func newProgress(label string, quiet bool) *progress {
+	if quiet || !printer.IsTerminal(os.Stderr) {
+		return nil
+	}
+	return &progress{label: label}
+}
+
+// Update shows that done out of total variables were processed.
+func (p *progress) Update(done, total int) {
+	if p == nil {
+		return
+	}
+	line := fmt.Sprintf("%s… %d/%d", p.label, done, total)
+	// Overwrite the remainder of a longer previous line.
+	padding := p.width - utf8.RuneCountInString(line)
+	if padding < 0 {
+		padding = 0
+	}
+	p.width = utf8.RuneCountInString(line)
+	_, _ = fmt.Fprint(os.Stderr, "\r"+line+strings.Repeat(" ", padding))
+}
+
+// Done removes the progress line.
+func (p *progress) Done() {
+	if p == nil || p.width == 0 {
+		return
+	}
+	_, _ = fmt.Fprint(os.Stderr, "\r"+strings.Repeat(" ", p.width)+"\r")
+	p.width = 0
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build windows
+
+package efibootctl
+
+import (
+	"github.com/Microsoft/go-winio"
+	"golang.org/x/sys/windows"
+)
+
+// shutdownPrivilege is required to restart the system.
+const shutdownPrivilege = "SeShutdownPrivilege"
+
+// rebootSystem asks Windows to restart the system.  The applications
+// are closed as during a regular restart.
+func rebootSystem() error {
+	return winio.RunWithPrivilege(shutdownPrivilege, func() error {
+		return windows.ExitWindowsEx(windows.EWX_REBOOT,
+			windows.SHTDN_REASON_MAJOR_OPERATINGSYSTEM|windows.SHTDN_REASON_MINOR_UPGRADE|windows.SHTDN_REASON_FLAG_PLANNED)
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+)
+
+var (
+	ErrEmptyDescription   = errors.New("description must not be empty")
+	ErrInvalidDescription = errors.New("description must not contain Null characters")
+)
+
+// Rename replaces the description of the boot entry with the given
+// index, leaving its device paths and optional data untouched.
+func Rename(c efivario.Context, index uint16, desc string) error {
+	switch {
+	case desc == "":
+		return ErrEmptyDescription
+	case strings.ContainsRune(desc, 0):
+		return ErrInvalidDescription
+	}
+
+	return BootOptions.Update(c, index, func(lo *LoadOption) error {
+		lo.SetDescription(desc)
+		return nil
+	})
+}
+
+func renameE(args []string) (err error) {
+	fs, out := newWriteFlagSet("rename")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return errors.New("rename: a boot entry index and a description are required")
+	}
+
+	index, err := out.parseIndex(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	if err := Rename(c, index, fs.Arg(1)); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
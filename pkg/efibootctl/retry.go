@@ -0,0 +1,75 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"syscall"
+	"time"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+const (
+	// defaultRetries is the number of times a failed write is
+	// retried by default.
+	defaultRetries = 3
+
+	// defaultRetryDelay is the delay before the first retry, every
+	// further retry waits twice as long as the one before.
+	defaultRetryDelay = 100 * time.Millisecond
+)
+
+// isTransientError reports whether a write failing with err might
+// succeed when retried.  Some firmware reports a busy variable
+// store for writes following each other quickly.
+func isTransientError(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EAGAIN)
+}
+
+// retryingContext is an efivario.Context retrying writes which fail
+// with a transient error.
+type retryingContext struct {
+	efivario.Context
+
+	retries int
+	delay   time.Duration
+	sleep   func(time.Duration)
+}
+
+var _ efivario.Context = &retryingContext{}
+
+// newRetryingContext wraps c to retry writes up to the given number
+// of times with exponential backoff starting at delay.  c is
+// returned as is if retries is 0.
+func newRetryingContext(c efivario.Context, retries int, delay time.Duration) efivario.Context {
+	if retries <= 0 {
+		return c
+	}
+	return &retryingContext{Context: c, retries: retries, delay: delay, sleep: time.Sleep}
+}
+
+func (c *retryingContext) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) (err error) {
+	delay := c.delay
+	for attempt := 0; ; attempt++ {
+		err = c.Context.Set(name, guid, attrs, value)
+		if err == nil || attempt == c.retries || !isTransientError(err) {
+			return err
+		}
+		c.sleep(delay)
+		delay *= 2
+	}
+}
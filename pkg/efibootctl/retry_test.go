@@ -0,0 +1,124 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+// failingStore is a MemoryVarStore failing the first writes with
+// an error.
+type failingStore struct {
+	*MemoryVarStore
+
+	failures int
+	err      error
+	calls    int
+}
+
+func (s *failingStore) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return s.err
+	}
+	return s.MemoryVarStore.Set(name, guid, attrs, value)
+}
+
+func TestRetryingContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		err        error
+		retries    int
+		wantErr    error
+		wantCalls  int
+		wantDelays []time.Duration
+	}{
+		{
+			name:      "success",
+			retries:   3,
+			wantCalls: 1,
+		},
+		{
+			name:       "succeeds on the last retry",
+			failures:   3,
+			err:        syscall.EBUSY,
+			retries:    3,
+			wantCalls:  4,
+			wantDelays: []time.Duration{10, 20, 40},
+		},
+		{
+			name:       "succeeds on the first retry",
+			failures:   1,
+			err:        syscall.EAGAIN,
+			retries:    3,
+			wantCalls:  2,
+			wantDelays: []time.Duration{10},
+		},
+		{
+			name:       "gives up",
+			failures:   4,
+			err:        syscall.EBUSY,
+			retries:    3,
+			wantErr:    syscall.EBUSY,
+			wantCalls:  4,
+			wantDelays: []time.Duration{10, 20, 40},
+		},
+		{
+			name:      "does not retry permanent errors",
+			failures:  1,
+			err:       syscall.EPERM,
+			retries:   3,
+			wantErr:   syscall.EPERM,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &failingStore{MemoryVarStore: NewMemoryVarStore(), failures: tt.failures, err: tt.err}
+
+			var delays []time.Duration
+			c := newRetryingContext(s, tt.retries, 10).(*retryingContext)
+			c.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+			err := efivars.BootNext.Set(c, 5)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Set() = %v, want %v", err, tt.wantErr)
+			}
+			if s.calls != tt.wantCalls {
+				t.Errorf("writes = %d, want %d", s.calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(delays, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", delays, tt.wantDelays)
+			}
+
+			_, next, err := efivars.BootNext.Get(s.MemoryVarStore)
+			switch {
+			case tt.wantErr == nil && (err != nil || next != 5):
+				t.Errorf("BootNext = %d, %v, want 5", next, err)
+			case tt.wantErr != nil && !errors.Is(err, efivario.ErrNotFound):
+				t.Errorf("BootNext = %d, %v, want ErrNotFound", next, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+var (
+	bootIndexType     = reflect.TypeOf(BootIndex(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// typeSchema returns the JSON Schema of the values of type t as
+// written by encoding/json.  It is derived from the types themselves
+// so that it cannot drift from what --json writes.
+func typeSchema(t reflect.Type) map[string]any {
+	switch {
+	case t.Kind() == reflect.Ptr:
+		return nullable(typeSchema(t.Elem()))
+	case t == bootIndexType:
+		return map[string]any{"type": "string", "pattern": "^[0-9A-F]{4}$"}
+	case t.Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		// Nil slices are written as null.
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(map[string]any{"type": "string", "contentEncoding": "base64"})
+		}
+		return nullable(map[string]any{"type": "array", "items": typeSchema(t.Elem())})
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	panic(fmt.Sprintf("no JSON Schema for %s", t))
+}
+
+// structSchema returns the JSON Schema of a struct.  Fields are
+// described by their doc tag.
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s := typeSchema(f.Type)
+		if doc := f.Tag.Get("doc"); doc != "" {
+			s["description"] = doc
+		}
+		properties[name] = s
+		if opts != "omitempty" {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// nullable allows null in addition to the values of s.
+func nullable(s map[string]any) map[string]any {
+	s["type"] = []any{s["type"], "null"}
+	return s
+}
+
+// listJSONSchema returns the JSON Schema of the document written by
+// list --json.
+func listJSONSchema() map[string]any {
+	s := typeSchema(reflect.TypeOf(jsonDocument{}))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = "efibootctl list --json"
+	s["properties"].(map[string]any)["schemaVersion"].(map[string]any)["const"] = jsonSchemaVersion
+	return s
+}
+
+func jsonSchemaE(args []string) (err error) {
+	fs, out := newOutputFlagSet("json-schema")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("json-schema: unexpected arguments")
+	}
+
+	enc := json.NewEncoder(out.writer())
+	enc.SetIndent("", "  ")
+	return enc.Encode(listJSONSchema())
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+// validateSchema validates the decoded JSON value v against the
+// decoded JSON Schema s.  Only the keywords used by typeSchema are
+// supported.
+func validateSchema(s map[string]any, v any, path string) error {
+	if want, ok := s["const"]; ok && fmt.Sprint(want) != fmt.Sprint(v) {
+		return fmt.Errorf("%s: %v, want %v", path, v, want)
+	}
+
+	types, ok := s["type"].([]any)
+	if !ok {
+		types = []any{s["type"]}
+	}
+	var typ string
+	switch v.(type) {
+	case nil:
+		typ = "null"
+	case bool:
+		typ = "boolean"
+	case string:
+		typ = "string"
+	case float64:
+		typ = "number"
+		if f := v.(float64); f == float64(int64(f)) {
+			typ = "integer"
+		}
+	case []any:
+		typ = "array"
+	case map[string]any:
+		typ = "object"
+	}
+	matched := false
+	for _, t := range types {
+		matched = matched || t == typ
+	}
+	if !matched {
+		return fmt.Errorf("%s: %s, want %v", path, typ, types)
+	}
+
+	switch v := v.(type) {
+	case string:
+		if pattern, ok := s["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(v) {
+			return fmt.Errorf("%s: %q does not match %s", path, v, pattern)
+		}
+		if s["contentEncoding"] == "base64" {
+			if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if err := validateSchema(s["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		properties := s["properties"].(map[string]any)
+		for _, name := range s["required"].([]any) {
+			if _, ok := v[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing property %s", path, name)
+			}
+		}
+		for name, value := range v {
+			ps, ok := properties[name]
+			if !ok {
+				if s["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected property %s", path, name)
+				}
+				continue
+			}
+			if err := validateSchema(ps.(map[string]any), value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decodeJSON round-trips v through encoding/json.
+func decodeJSON(t *testing.T, v any) any {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestListJSONMatchesSchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(t *testing.T) *MemoryVarStore
+	}{
+		{
+			name:  "entries",
+			store: func(t *testing.T) *MemoryVarStore { return newTestStore(t, "a", "b") },
+		},
+		{
+			name: "BootNext and optional data",
+			store: func(t *testing.T) *MemoryVarStore {
+				s := newTestStore(t, "a")
+				lo := newTestLoadOption("b")
+				lo.OptionalData = []byte("quiet")
+				if _, err := CreateBootEntry(s, lo); err != nil {
+					t.Fatal(err)
+				}
+				if err := efivars.BootNext.Set(s, 1); err != nil {
+					t.Fatal(err)
+				}
+				return s
+			},
+		},
+		{
+			name:  "no entries",
+			store: func(t *testing.T) *MemoryVarStore { return newTestStore(t) },
+		},
+	}
+
+	schema := decodeJSON(t, listJSONSchema()).(map[string]any)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runCommand(t, tt.store(t), listE, "--json")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var doc any
+			if err := json.Unmarshal([]byte(got), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if err := validateSchema(schema, doc, "$"); err != nil {
+				t.Errorf("list --json does not match the schema: %v\n%s", err, got)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaRejectsMismatches(t *testing.T) {
+	schema := decodeJSON(t, listJSONSchema()).(map[string]any)
+	valid := func() map[string]any {
+		return decodeJSON(t, &jsonDocument{
+			SchemaVersion: jsonSchemaVersion,
+			BootOrder:     []BootIndex{0},
+			Entries:       []jsonEntry{{Index: 0, Description: "a"}},
+		}).(map[string]any)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(doc map[string]any)
+	}{
+		{"schema version", func(doc map[string]any) { doc["schemaVersion"] = float64(jsonSchemaVersion + 1) }},
+		{"unexpected property", func(doc map[string]any) { doc["extra"] = true }},
+		{"missing property", func(doc map[string]any) { delete(doc, "bootCurrent") }},
+		{"index pattern", func(doc map[string]any) { doc["bootOrder"] = []any{"00G0"} }},
+		{"entry type", func(doc map[string]any) { doc["entries"] = []any{"a"} }},
+	}
+
+	if err := validateSchema(schema, valid(), "$"); err != nil {
+		t.Fatalf("valid document rejected: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := valid()
+			tt.modify(doc)
+			if err := validateSchema(schema, doc, "$"); err == nil {
+				t.Error("validateSchema() = nil, want an error")
+			}
+		})
+	}
+}
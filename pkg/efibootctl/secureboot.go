@@ -0,0 +1,178 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+const (
+	SecureBootName = "SecureBoot"
+	SetupModeName  = "SetupMode"
+)
+
+// SecureBootState summarizes the SecureBoot and SetupMode
+// variables.
+type SecureBootState int
+
+const (
+	// SecureBootUnsupported means the firmware does not expose
+	// the SecureBoot variable.
+	SecureBootUnsupported SecureBootState = iota
+
+	// SecureBootDisabled means the platform is in user mode with
+	// Secure Boot turned off.
+	SecureBootDisabled
+
+	// SecureBootEnabled means images are verified before they
+	// are booted.
+	SecureBootEnabled
+
+	// SecureBootSetupMode means no platform key is enrolled and
+	// the Secure Boot keys can be changed without authentication.
+	SecureBootSetupMode
+)
+
+func (s SecureBootState) String() string {
+	switch s {
+	case SecureBootUnsupported:
+		return "unsupported"
+	case SecureBootDisabled:
+		return "disabled"
+	case SecureBootEnabled:
+		return "enabled"
+	case SecureBootSetupMode:
+		return "setup"
+	}
+	return fmt.Sprintf("SecureBootState(%d)", int(s))
+}
+
+// keyword is a string printed without quotes.
+type keyword string
+
+func (k keyword) PrettyPrint(p *printer.Printer) {
+	p.ColorPrint(string(k), printer.BoolColor)
+}
+
+// readBoolVariable reads a single byte boolean variable from the
+// global namespace.  The returned ok is false if the variable does
+// not exist.
+func readBoolVariable(c efivario.Context, name string) (value, ok bool, err error) {
+	data, err := readOptionalVariable(c, name)
+	if err != nil || data == nil {
+		return false, false, err
+	}
+	if len(data) != 1 {
+		return false, false, fmt.Errorf("%s: unexpected size %d", name, len(data))
+	}
+	return data[0] != 0, true, nil
+}
+
+// SecureBootStatus returns the Secure Boot state of the platform.
+func SecureBootStatus(c efivario.Context) (SecureBootState, error) {
+	secureBoot, ok, err := readBoolVariable(c, SecureBootName)
+	if err != nil || !ok {
+		return SecureBootUnsupported, err
+	}
+
+	setupMode, _, err := readBoolVariable(c, SetupModeName)
+	switch {
+	case err != nil:
+		return SecureBootUnsupported, err
+	case secureBoot:
+		return SecureBootEnabled, nil
+	case setupMode:
+		return SecureBootSetupMode, nil
+	}
+	return SecureBootDisabled, nil
+}
+
+// printSecureBoot prints the SecureBoot and SetupMode variables if
+// they exist.
+func printSecureBoot(c efivario.Context, p *printer.Printer) error {
+	secureBoot, ok, err := readBoolVariable(c, SecureBootName)
+	if err != nil {
+		return err
+	}
+	if ok {
+		value := keyword("disabled")
+		if secureBoot {
+			value = "enabled"
+		}
+		p.PrintFieldValue(SecureBootName, value)
+	}
+
+	setupMode, ok, err := readBoolVariable(c, SetupModeName)
+	if err != nil {
+		return err
+	}
+	if ok {
+		value := keyword("user")
+		if setupMode {
+			value = "setup"
+		}
+		p.PrintFieldValue(SetupModeName, value)
+	}
+	return nil
+}
+
+func secureBootE(args []string) (err error) {
+	fs, out := newOutputFlagSet("secureboot")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return errors.New("secureboot: no arguments are accepted")
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	state, err := SecureBootStatus(c)
+	if err != nil {
+		return fmt.Errorf("secureboot: %w", err)
+	}
+
+	p := out.newPrinter()
+	p.PrintFieldValue("State", keyword(state.String()))
+	for _, db := range SignatureDatabases {
+		sigs, ok, err := ReadSignatureDatabase(c, db)
+		if err != nil {
+			return fmt.Errorf("secureboot: %w", err)
+		}
+		if !ok {
+			p.PrintFieldValue(db.Name, keyword("not set"))
+			continue
+		}
+
+		p.PrintFieldValue(db.Name, countSignatures(sigs))
+		if out.verbose {
+			for i, s := range sigs {
+				p.PrintFieldValue(fmt.Sprintf("%s[%d]", db.Name, i), signatureDetails(s))
+			}
+		}
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+func TestReadBoolVariable(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    bool
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "missing"},
+		{name: "enabled", data: []byte{1}, want: true, wantOK: true},
+		{name: "disabled", data: []byte{0}, wantOK: true},
+		{name: "too long", data: []byte{1, 0}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryVarStore()
+			if tt.data != nil {
+				if err := s.Set("Var", efivars.GlobalVariable, defaultAttributes, tt.data); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, ok, err := readBoolVariable(s, "Var")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readBoolVariable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("readBoolVariable() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
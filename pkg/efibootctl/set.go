@@ -0,0 +1,109 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+)
+
+// maxVariableSize is the largest value written to a variable.
+// Firmware commonly refuses single variables larger than a few KiB,
+// so larger values are rejected before they reach it.
+const maxVariableSize = 4096
+
+var (
+	// ErrVariableTooLarge is returned for values exceeding
+	// maxVariableSize.
+	ErrVariableTooLarge = fmt.Errorf("value exceeds %d bytes", maxVariableSize)
+
+	// ErrWriteNotPermitted is returned when the operating system
+	// refuses to write a variable.
+	ErrWriteNotPermitted = errors.New("write not permitted, the variable might be read-only or protected by the firmware")
+)
+
+// SetVariable writes value with the given attributes to the named
+// variable.
+func SetVariable(c efivario.Context, name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	if len(value) > maxVariableSize {
+		return fmt.Errorf("%s: %w", name, ErrVariableTooLarge)
+	}
+	if err := c.Set(name, guid, attrs, value); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("%s: %w", name, ErrWriteNotPermitted)
+		}
+		return err
+	}
+	return nil
+}
+
+func setE(args []string) (err error) {
+	fs, out := newWriteFlagSet("set")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	fromFile := fs.String("from-file", "", "`path` to the file holding the raw value")
+	attrs := AttributeFlags(defaultAttributes)
+	fs.Var(&attrs, "attrs", "comma separated `attributes` of the variable, e.g. NV,BS,RT")
+	force := fs.Bool("force", false, "actually write the variable")
+	namespace := newGUIDFlag(fs)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(positional) != 1:
+		return errors.New("set: exactly one variable name is required")
+	case *fromFile == "":
+		return errors.New("set: --from-file is required")
+	}
+
+	name, guid, err := namespace.variableName(positional[0])
+	if err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+
+	value, err := os.ReadFile(*fromFile)
+	if err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+	if len(value) > maxVariableSize {
+		return fmt.Errorf("set: %s: %w", *fromFile, ErrVariableTooLarge)
+	}
+
+	// Show what is about to be written before writing anything.
+	if !out.dryRun {
+		p := out.newPrinter()
+		printVariableWrite(p, name, guid, efivario.Attributes(attrs), value)
+		_, _ = fmt.Fprint(out.writer(), p.String())
+
+		if !*force {
+			return errors.New("set: not written, pass --force to write the variable")
+		}
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	if err := SetVariable(c, name, guid, efivario.Attributes(attrs), value); err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+func TestSetReadsBack(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr error
+	}{
+		{name: "small", size: 16},
+		{name: "larger than 2 KiB", size: 3000},
+		{name: "largest", size: maxVariableSize},
+		{name: "too large", size: maxVariableSize + 1, wantErr: ErrVariableTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := bytes.Repeat([]byte{0x5a}, tt.size)
+			path := filepath.Join(t.TempDir(), "value")
+			if err := os.WriteFile(path, value, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			s := NewMemoryVarStore()
+			_, err := runCommand(t, s, setE, "--force", "--from-file", path, "Var")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("set = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			attrs, got, err := readVariable(s, "Var", efivars.GlobalVariable)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if attrs != defaultAttributes || !bytes.Equal(got, value) {
+				t.Errorf("read back %v and %d bytes, want %v and the %d bytes written", attrs, len(got), defaultAttributes, len(value))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"go.uber.org/multierr"
+)
+
+// SetOptionalData replaces the optional data of the boot entry
+// with the given index, leaving its description and device paths
+// untouched.
+func SetOptionalData(c efivario.Context, index uint16, data []byte) error {
+	return BootOptions.Update(c, index, func(lo *LoadOption) error {
+		lo.OptionalData = data
+		return nil
+	})
+}
+
+func setArgsE(args []string) (err error) {
+	fs, out := newWriteFlagSet("set-args")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	rawHex := fs.Bool("raw-hex", false, "interpret the arguments as hex encoded bytes")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 2 {
+		return errors.New("set-args: a boot entry index and the arguments are required")
+	}
+
+	index, err := out.parseIndex(positional[0])
+	if err != nil {
+		return fmt.Errorf("set-args: %w", err)
+	}
+
+	var data []byte
+	if *rawHex {
+		if data, err = hex.DecodeString(positional[1]); err != nil {
+			return fmt.Errorf("set-args: invalid hex data: %w", err)
+		}
+	} else {
+		data = encodeUTF16(positional[1])
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	if err := SetOptionalData(c, index, data); err != nil {
+		return fmt.Errorf("set-args: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// ImageSecurityDatabase is the namespace of the db and dbx
+// signature databases.
+var ImageSecurityDatabase = efiguid.MustFromString("d719b2cb-3d3a-4596-a3bc-dad00e67656f")
+
+// SignatureDatabase names an EFI variable holding a list of
+// EFI_SIGNATURE_LIST structures.
+type SignatureDatabase struct {
+	Name string
+	GUID efiguid.GUID
+}
+
+// SignatureDatabases are the Secure Boot signature databases in the
+// order of their hierarchy.
+var SignatureDatabases = []SignatureDatabase{
+	{Name: "PK", GUID: efivars.GlobalVariable},
+	{Name: "KEK", GUID: efivars.GlobalVariable},
+	{Name: "db", GUID: ImageSecurityDatabase},
+	{Name: "dbx", GUID: ImageSecurityDatabase},
+}
+
+// signatureTypes maps the known EFI_SIGNATURE_LIST types to the
+// names they are printed under.
+var signatureTypes = map[efiguid.GUID]string{
+	efiguid.MustFromString("c1c41626-504c-4092-aca9-41f936934328"): "SHA256",
+	efiguid.MustFromString("3c5766e8-269c-4e34-aa14-ed776e85b3b6"): "RSA2048",
+	efiguid.MustFromString("e2b36190-879b-4a3d-ad8d-f2e7bba32784"): "RSA2048_SHA256",
+	efiguid.MustFromString("826ca512-cf10-4ac9-b187-be01496631bd"): "SHA1",
+	efiguid.MustFromString("67f8444f-8743-48f1-a328-1eaab8736080"): "RSA2048_SHA1",
+	efiguid.MustFromString("a5c059a1-94e4-4aa7-87b5-ab155c2bf072"): "X509",
+	efiguid.MustFromString("0b6e5233-a65c-44c9-9407-d9ab83bfc8bd"): "SHA224",
+	efiguid.MustFromString("ff3e5307-9fd0-48c9-85f1-8ad56c701e01"): "SHA384",
+	efiguid.MustFromString("093e0fae-a6c4-4f50-9f1b-d41c2b89c19a"): "SHA512",
+	efiguid.MustFromString("3bd2a492-96c0-4079-b420-fcf98ef103ed"): "X509_SHA256",
+	efiguid.MustFromString("7076876e-80c2-4ee6-aad2-28b349a6865b"): "X509_SHA384",
+	efiguid.MustFromString("446dbf63-2502-4cda-bcfa-2465d2b0fe9d"): "X509_SHA512",
+}
+
+// signatureTypeName returns the name of the given signature type.
+// Unknown types are named by their GUID.
+func signatureTypeName(t efiguid.GUID) string {
+	if name, ok := signatureTypes[t]; ok {
+		return name
+	}
+	return strings.ToLower(t.String())
+}
+
+// signatureListHeaderSize is the size of the fixed part of an
+// EFI_SIGNATURE_LIST: the type GUID followed by the list, header and
+// signature sizes.
+const signatureListHeaderSize = 16 + 4 + 4 + 4
+
+// Signature is a single EFI_SIGNATURE_DATA entry of a signature
+// database.
+type Signature struct {
+	// Type is the EFI_SIGNATURE_LIST type the signature is
+	// listed under.
+	Type efiguid.GUID
+
+	// Owner identifies the agent which added the signature.
+	Owner efiguid.GUID
+
+	// Data is the certificate or hash.
+	Data []byte
+}
+
+// ParseSignatureDatabase parses the EFI_SIGNATURE_LIST structures
+// of a signature database into its signatures.
+func ParseSignatureDatabase(data []byte) (out []Signature, err error) {
+	for offset := 0; offset < len(data); {
+		rest := data[offset:]
+		if len(rest) < signatureListHeaderSize {
+			return nil, fmt.Errorf("signature list at offset %d: truncated header", offset)
+		}
+
+		var t efiguid.GUID
+		copy(t[:], rest)
+		listSize := binary.LittleEndian.Uint32(rest[16:])
+		headerSize := binary.LittleEndian.Uint32(rest[20:])
+		signatureSize := binary.LittleEndian.Uint32(rest[24:])
+
+		switch {
+		case listSize < signatureListHeaderSize || uint64(listSize) > uint64(len(rest)):
+			return nil, fmt.Errorf("signature list at offset %d: invalid size %d", offset, listSize)
+		case signatureSize <= 16:
+			return nil, fmt.Errorf("signature list at offset %d: invalid signature size %d", offset, signatureSize)
+		case uint64(headerSize) > uint64(listSize-signatureListHeaderSize):
+			return nil, fmt.Errorf("signature list at offset %d: invalid header size %d", offset, headerSize)
+		}
+
+		entries := rest[signatureListHeaderSize+int(headerSize) : listSize]
+		if len(entries)%int(signatureSize) != 0 {
+			return nil, fmt.Errorf("signature list at offset %d: %d bytes are not a multiple of the signature size %d", offset, len(entries), signatureSize)
+		}
+		for ; len(entries) > 0; entries = entries[signatureSize:] {
+			s := Signature{Type: t, Data: entries[16:signatureSize]}
+			copy(s.Owner[:], entries)
+			out = append(out, s)
+		}
+
+		offset += int(listSize)
+	}
+	return out, nil
+}
+
+// ReadSignatureDatabase reads and parses the given signature
+// database.  The returned ok is false if the variable does not
+// exist, as is the case for PK and KEK in setup mode.
+func ReadSignatureDatabase(c efivario.Context, db SignatureDatabase) (sigs []Signature, ok bool, err error) {
+	_, data, err := readVariable(c, db.Name, db.GUID)
+	if err != nil {
+		if errors.Is(err, efivario.ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	sigs, err = ParseSignatureDatabase(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", db.Name, err)
+	}
+	return sigs, true, nil
+}
+
+// signatureCount is the number of signatures of one type.
+type signatureCount struct {
+	name  string
+	count int
+}
+
+// signatureCounts summarizes a signature database by the number of
+// signatures of every type, in the order the types first appear.
+type signatureCounts []signatureCount
+
+// countSignatures returns the summary of the given signatures.
+func countSignatures(sigs []Signature) (out signatureCounts) {
+	index := map[efiguid.GUID]int{}
+	for _, s := range sigs {
+		i, ok := index[s.Type]
+		if !ok {
+			i = len(out)
+			index[s.Type] = i
+			out = append(out, signatureCount{name: signatureTypeName(s.Type)})
+		}
+		out[i].count++
+	}
+	return
+}
+
+func (s signatureCounts) PrettyPrint(p *printer.Printer) {
+	if len(s) == 0 {
+		p.ColorPrint("empty", printer.BoolColor)
+		return
+	}
+	for i, c := range s {
+		if i > 0 {
+			p.Print(", ")
+		}
+		p.ColorPrint(fmt.Sprint(c.count), printer.IntegerColor)
+		p.Print(" " + c.name)
+	}
+}
+
+// ownerGUID is the GUID of the agent owning a signature.
+type ownerGUID efiguid.GUID
+
+func (g ownerGUID) PrettyPrint(p *printer.Printer) {
+	p.ColorPrint(strings.ToLower(efiguid.GUID(g).String()), printer.GUIDColor)
+}
+
+// certificateInfo is printed for X.509 certificates in verbose
+// mode.
+type certificateInfo struct {
+	Subject   string
+	Issuer    string
+	Serial    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	Owner     ownerGUID
+}
+
+// hashInfo is printed for all other signatures in verbose mode.
+type hashInfo struct {
+	Type  string
+	Data  string
+	Owner ownerGUID
+}
+
+// signatureDetails returns the value printed for the given
+// signature in verbose mode.  Certificates which cannot be parsed
+// are printed like hashes.
+func signatureDetails(s Signature) any {
+	if signatureTypeName(s.Type) == "X509" {
+		if cert, err := x509.ParseCertificate(s.Data); err == nil {
+			return certificateInfo{
+				Subject:   cert.Subject.String(),
+				Issuer:    cert.Issuer.String(),
+				Serial:    cert.SerialNumber.Text(16),
+				NotBefore: cert.NotBefore,
+				NotAfter:  cert.NotAfter,
+				Owner:     ownerGUID(s.Owner),
+			}
+		}
+	}
+	return hashInfo{
+		Type:  signatureTypeName(s.Type),
+		Data:  hex.EncodeToString(s.Data),
+		Owner: ownerGUID(s.Owner),
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+func sysprepE(args []string) (err error) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "create":
+			return createLoadOption("sysprep create", SysPrepOptions, args[1:])
+		case "delete":
+			return deleteLoadOptions("sysprep delete", SysPrepOptions, args[1:])
+		}
+	}
+
+	fs, out := newOutputFlagSet("sysprep")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	p := out.newPrinter()
+	if err := out.printLoadOptions(c, p, SysPrepOptions); err != nil {
+		return fmt.Errorf("sysprep: %w", err)
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// parseTemplate parses the text given with --template.  The error
+// names the offending expression and its position within text.
+func parseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("--template: %w", err)
+	}
+	return t, nil
+}
+
+// printTemplate executes t against the boot manager state with the
+// given boot entries and writes the result to w.  The data is a
+// jsonDocument, so a template has access to .BootCurrent, .BootNext,
+// .BootOrder and .Entries, each entry providing .Index, .Description,
+// .Active, .DevicePath and .OptionalData.  Nothing is written if the
+// template fails.
+func printTemplate(c efivario.Context, w io.Writer, t *template.Template, entries []BootEntryInfo, bootOrder []uint16) error {
+	doc, err := newJSONDocument(c, entries, bootOrder)
+	if err != nil {
+		return fmt.Errorf("%s: %w", t.Name(), err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, doc); err != nil {
+		return fmt.Errorf("%s: --template: %w", t.Name(), err)
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
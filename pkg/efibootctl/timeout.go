@@ -0,0 +1,162 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+
+	"github.com/0x5a17ed/efibootctl/pkg/printer"
+)
+
+// TimeoutName is the name of the variable holding the firmware's
+// boot manager timeout in seconds.
+const TimeoutName = "Timeout"
+
+// TimeoutSeconds is the firmware's boot manager timeout.
+type TimeoutSeconds uint16
+
+func (t TimeoutSeconds) PrettyPrint(p *printer.Printer) {
+	p.ColorPrint(strconv.Itoa(int(t)), printer.IntegerColor)
+	p.Print(" seconds")
+}
+
+// Duration returns the timeout as a time.Duration.
+func (t TimeoutSeconds) Duration() time.Duration {
+	return time.Duration(t) * time.Second
+}
+
+// ParseTimeout parses a timeout given either as a plain number of
+// seconds or as a duration with a unit, e.g. "5s" or "2m".  As the
+// Timeout variable holds whole seconds, sub-second durations are
+// rejected, as are timeouts above 65535 seconds.
+func ParseTimeout(s string) (TimeoutSeconds, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		n, nerr := strconv.ParseUint(s, 10, 64)
+		if nerr != nil {
+			return 0, fmt.Errorf("invalid timeout %q", s)
+		}
+		if n > math.MaxUint16 {
+			return 0, fmt.Errorf("timeout %q exceeds %d seconds", s, math.MaxUint16)
+		}
+		return TimeoutSeconds(n), nil
+	}
+
+	switch {
+	case d < 0:
+		return 0, fmt.Errorf("timeout %q is negative", s)
+	case d%time.Second != 0:
+		return 0, fmt.Errorf("timeout %q is not a whole number of seconds", s)
+	case d > math.MaxUint16*time.Second:
+		return 0, fmt.Errorf("timeout %q exceeds %d seconds", s, math.MaxUint16)
+	}
+	return TimeoutSeconds(d / time.Second), nil
+}
+
+// GetTimeout returns the current boot manager timeout.  The
+// returned bool is false if the Timeout variable is not set.
+func GetTimeout(c efivario.Context) (TimeoutSeconds, bool, error) {
+	_, data, err := efivario.ReadAll(c, TimeoutName, efivars.GlobalVariable)
+	if err != nil {
+		if errors.Is(err, efivario.ErrNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if len(data) != 2 {
+		return 0, false, fmt.Errorf("%s: unexpected size %d", TimeoutName, len(data))
+	}
+	return TimeoutSeconds(binary.LittleEndian.Uint16(data)), true, nil
+}
+
+// SetTimeout sets the boot manager timeout.  A timeout of zero
+// makes the firmware boot the first entry immediately.
+func SetTimeout(c efivario.Context, t TimeoutSeconds) error {
+	var data [2]byte
+	binary.LittleEndian.PutUint16(data[:], uint16(t))
+	return c.Set(TimeoutName, efivars.GlobalVariable, defaultAttributes, data[:])
+}
+
+// ClearTimeout removes the Timeout variable.  It is not an error
+// if Timeout is not set.
+func ClearTimeout(c efivario.Context) error {
+	err := c.Delete(TimeoutName, efivars.GlobalVariable)
+	if err != nil && !errors.Is(err, efivario.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func timeoutE(args []string) (err error) {
+	fs, out := newWriteFlagSet("timeout")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	clear := fs.Bool("clear", false, "remove the Timeout variable instead of setting it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var seconds TimeoutSeconds
+	switch {
+	case fs.NArg() > 1:
+		return errors.New("timeout: at most one timeout value is allowed")
+	case *clear && fs.NArg() != 0:
+		return errors.New("timeout: --clear does not take a timeout value")
+	case fs.NArg() == 1:
+		if seconds, err = ParseTimeout(fs.Arg(0)); err != nil {
+			return fmt.Errorf("timeout: %w", err)
+		}
+		if seconds == 0 {
+			_, _ = fmt.Fprintln(os.Stderr, "warning: a timeout of 0 makes the firmware boot immediately without showing the boot menu")
+		}
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	switch {
+	case *clear:
+		err = ClearTimeout(c)
+	case fs.NArg() == 1:
+		err = SetTimeout(c, seconds)
+	}
+	if err != nil {
+		return fmt.Errorf("timeout: %w", err)
+	}
+
+	if *clear {
+		return nil
+	}
+
+	timeout, ok, err := GetTimeout(c)
+	if err != nil {
+		return fmt.Errorf("timeout: %w", err)
+	}
+	if ok {
+		p := out.newPrinter()
+		p.PrintFieldValue("Timeout", timeout)
+		_, _ = fmt.Fprint(out.writer(), p.String())
+	}
+	return nil
+}
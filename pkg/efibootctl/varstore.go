@@ -0,0 +1,177 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/0x5a17ed/itkit"
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario"
+)
+
+// VarStore is the storage of EFI variables the commands operate
+// on.  The efivario.Context interface already covers the Get, Set,
+// Delete and VariableNames operations needed, so the contexts of
+// the host and MemoryVarStore can be used interchangeably wherever
+// an efivario.Context is taken.
+type VarStore = efivario.Context
+
+// openVarStore returns the VarStore the commands operate on.  It is
+// a variable, so that the commands can be run against a
+// MemoryVarStore instead of the variables of the host.
+var openVarStore func() VarStore = efivario.NewDefaultContext
+
+// maxReadSize bounds the buffer readVariable grows while looking
+// for the size of a variable.
+const maxReadSize = 1 << 20
+
+// readVariable returns the attributes and value of a variable.
+// Unlike efivario.ReadAll, which returns a buffer of zeros for
+// values larger than 2 KiB, it grows the buffer until the value
+// fits and reports an error if it never does.
+func readVariable(c efivario.Context, name string, guid efiguid.GUID) (efivario.Attributes, []byte, error) {
+	size, err := c.GetSizeHint(name, guid)
+	if err != nil || size <= 0 {
+		size = 512
+	}
+	if size > maxReadSize {
+		size = maxReadSize
+	}
+
+	for {
+		buf := make([]byte, size)
+		attrs, n, err := c.Get(name, guid, buf)
+		switch {
+		case errors.Is(err, efivario.ErrInsufficientSpace) && size < maxReadSize:
+			size *= 2
+		case err != nil:
+			return 0, nil, err
+		default:
+			return attrs, buf[:n], nil
+		}
+	}
+}
+
+// memoryVariable is a variable held by a MemoryVarStore.
+type memoryVariable struct {
+	attrs efivario.Attributes
+	data  []byte
+}
+
+// MemoryVarStore is a VarStore keeping its variables in memory.
+// It never touches the variables of the host.
+type MemoryVarStore struct {
+	vars map[efivario.VariableNameItem]*memoryVariable
+}
+
+var _ VarStore = &MemoryVarStore{}
+
+// NewMemoryVarStore returns an empty MemoryVarStore.
+func NewMemoryVarStore() *MemoryVarStore {
+	return &MemoryVarStore{vars: map[efivario.VariableNameItem]*memoryVariable{}}
+}
+
+func (s *MemoryVarStore) lookup(name string, guid efiguid.GUID) (*memoryVariable, error) {
+	v, ok := s.vars[efivario.VariableNameItem{Name: name, GUID: guid}]
+	if !ok {
+		return nil, efivario.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *MemoryVarStore) Close() error {
+	return nil
+}
+
+func (s *MemoryVarStore) GetSizeHint(name string, guid efiguid.GUID) (int64, error) {
+	v, err := s.lookup(name, guid)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(v.data)), nil
+}
+
+func (s *MemoryVarStore) Get(name string, guid efiguid.GUID, out []byte) (efivario.Attributes, int, error) {
+	v, err := s.lookup(name, guid)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(out) < len(v.data) {
+		return 0, 0, efivario.ErrInsufficientSpace
+	}
+	return v.attrs, copy(out, v.data), nil
+}
+
+// Set writes the variable.  As with the firmware, writing an empty
+// value removes the variable, which is not an error if it does not
+// exist.
+func (s *MemoryVarStore) Set(name string, guid efiguid.GUID, attrs efivario.Attributes, value []byte) error {
+	if len(value) == 0 {
+		delete(s.vars, efivario.VariableNameItem{Name: name, GUID: guid})
+		return nil
+	}
+	s.vars[efivario.VariableNameItem{Name: name, GUID: guid}] = &memoryVariable{
+		attrs: attrs,
+		data:  append([]byte(nil), value...),
+	}
+	return nil
+}
+
+func (s *MemoryVarStore) Delete(name string, guid efiguid.GUID) error {
+	key := efivario.VariableNameItem{Name: name, GUID: guid}
+	if _, ok := s.vars[key]; !ok {
+		return efivario.ErrNotFound
+	}
+	delete(s.vars, key)
+	return nil
+}
+
+// VariableNames returns the names of all variables sorted by name
+// and GUID.
+func (s *MemoryVarStore) VariableNames() (efivario.VariableNameIterator, error) {
+	items := make([]efivario.VariableNameItem, 0, len(s.vars))
+	for key := range s.vars {
+		items = append(items, key)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].GUID.String() < items[j].GUID.String()
+	})
+	return &memoryNameIterator{items: items, pos: -1}, nil
+}
+
+// memoryNameIterator iterates over a snapshot of the variable
+// names, as taken by a MemoryVarStore or a dryRunContext.
+type memoryNameIterator struct {
+	items []efivario.VariableNameItem
+	pos   int
+}
+
+func (it *memoryNameIterator) Close() error                                    { return nil }
+func (it *memoryNameIterator) Iter() itkit.Iterator[efivario.VariableNameItem] { return it }
+func (it *memoryNameIterator) Err() error                                      { return nil }
+func (it *memoryNameIterator) Value() efivario.VariableNameItem                { return it.items[it.pos] }
+
+func (it *memoryNameIterator) Next() bool {
+	if it.pos+1 >= len(it.items) {
+		return false
+	}
+	it.pos++
+	return true
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+)
+
+func TestMemoryVarStoreSetEmptyValue(t *testing.T) {
+	s := NewMemoryVarStore()
+	if err := s.Set("Missing", efivars.GlobalVariable, defaultAttributes, nil); err != nil {
+		t.Errorf("Set() of an empty value on a missing variable = %v, want nil", err)
+	}
+
+	if err := s.Set("Var", efivars.GlobalVariable, defaultAttributes, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("Var", efivars.GlobalVariable, defaultAttributes, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetSizeHint("Var", efivars.GlobalVariable); !errors.Is(err, efivario.ErrNotFound) {
+		t.Errorf("GetSizeHint() after writing an empty value = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBootEntryOperations(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []string
+		op          func(c VarStore) error
+		wantErr     error
+		wantOrder   []uint16
+		wantIndices []uint16
+	}{
+		{
+			name:    "create appends to the order",
+			entries: []string{"a"},
+			op: func(c VarStore) error {
+				_, err := CreateBootEntry(c, newTestLoadOption("b"))
+				return err
+			},
+			wantOrder:   []uint16{0, 1},
+			wantIndices: []uint16{0, 1},
+		},
+		{
+			name:    "create reuses the lowest free index",
+			entries: []string{"a", "b", "c"},
+			op: func(c VarStore) error {
+				if err := DeleteBootEntries(c, 1); err != nil {
+					return err
+				}
+				_, err := CreateBootEntry(c, newTestLoadOption("d"))
+				return err
+			},
+			wantOrder:   []uint16{0, 2, 1},
+			wantIndices: []uint16{0, 1, 2},
+		},
+		{
+			name:        "delete drops the entries from the order",
+			entries:     []string{"a", "b", "c"},
+			op:          func(c VarStore) error { return DeleteBootEntries(c, 0, 2) },
+			wantOrder:   []uint16{1},
+			wantIndices: []uint16{1},
+		},
+		{
+			name:        "delete of a missing entry changes nothing",
+			entries:     []string{"a", "b"},
+			op:          func(c VarStore) error { return DeleteBootEntries(c, 1, 5) },
+			wantErr:     ErrBootEntryNotFound,
+			wantOrder:   []uint16{0, 1},
+			wantIndices: []uint16{0, 1},
+		},
+		{
+			name:        "order",
+			entries:     []string{"a", "b", "c"},
+			op:          func(c VarStore) error { return SetBootOrder(c, []uint16{2, 0, 1}) },
+			wantOrder:   []uint16{2, 0, 1},
+			wantIndices: []uint16{0, 1, 2},
+		},
+		{
+			name:    "order command",
+			entries: []string{"a", "b"},
+			op: func(c VarStore) error {
+				_, err := runCommand(t, c, orderE, "1,0")
+				return err
+			},
+			wantOrder:   []uint16{1, 0},
+			wantIndices: []uint16{0, 1},
+		},
+		{
+			name:    "order command rejects unknown entries",
+			entries: []string{"a", "b"},
+			op: func(c VarStore) error {
+				_, err := runCommand(t, c, orderE, "1,0,7")
+				return err
+			},
+			wantErr:     ErrBootEntryNotFound,
+			wantOrder:   []uint16{0, 1},
+			wantIndices: []uint16{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, tt.entries...)
+
+			if err := tt.op(s); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			order, err := GetBootOrder(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("BootOrder = %v, want %v", order, tt.wantOrder)
+			}
+
+			indices, err := BootEntryIndices(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(indices, tt.wantIndices) {
+				t.Errorf("indices = %v, want %v", indices, tt.wantIndices)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0x5a17ed/uefi/efi/efivario"
+	"github.com/0x5a17ed/uefi/efi/efivars"
+	"go.uber.org/multierr"
+)
+
+// BootOrderReport describes the references between BootOrder,
+// BootNext and the existing Boot#### variables.
+type BootOrderReport struct {
+	// BootOrder is the current BootOrder.
+	BootOrder []uint16
+
+	// Dangling are the indices in BootOrder without a Boot####
+	// variable.
+	Dangling []uint16
+
+	// DanglingNext is true if BootNext is set and refers to a
+	// missing Boot#### variable.
+	DanglingNext bool
+
+	// Unlisted are the indices of Boot#### variables which are
+	// not part of BootOrder.
+	Unlisted []uint16
+}
+
+// OK reports whether no dangling references were found.
+func (r *BootOrderReport) OK() bool {
+	return len(r.Dangling) == 0 && !r.DanglingNext
+}
+
+// FixedOrder returns the BootOrder without the dangling references.
+func (r *BootOrderReport) FixedOrder() []uint16 {
+	return MissingFromOrder(r.Dangling, r.BootOrder)
+}
+
+// VerifyBootOrder cross-checks BootOrder and BootNext against the
+// existing Boot#### variables.
+func VerifyBootOrder(c efivario.Context) (*BootOrderReport, error) {
+	existing, err := BootEntryIndices(c)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := GetBootOrder(c)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &BootOrderReport{
+		BootOrder: order,
+		Dangling:  MissingFromOrder(existing, order),
+		Unlisted:  MissingFromOrder(order, existing),
+	}
+
+	_, bootNext, err := efivars.BootNext.Get(c)
+	switch {
+	case err == nil:
+		r.DanglingNext = len(MissingFromOrder(existing, []uint16{bootNext})) != 0
+	case !errors.Is(err, efivario.ErrNotFound):
+		return nil, err
+	}
+	return r, nil
+}
+
+func verifyE(args []string) (err error) {
+	fs, out := newWriteFlagSet("verify")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	fix := fs.Bool("fix", false, "drop dangling references from BootOrder and clear a dangling BootNext")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := out.newContext()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	r, err := VerifyBootOrder(c)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	p := out.newPrinter()
+	if len(r.Dangling) > 0 {
+		p.PrintFieldValue("Dangling", toBootIndices(r.Dangling))
+	}
+	if r.DanglingNext {
+		p.PrintFieldValue("BootNext", "refers to a missing entry")
+	}
+	if len(r.Unlisted) > 0 {
+		p.PrintFieldValue("Unlisted", toBootIndices(r.Unlisted))
+	}
+	_, _ = fmt.Fprint(out.writer(), p.String())
+
+	if r.OK() {
+		return nil
+	}
+	if !*fix {
+		return errors.New("verify: dangling references found, pass --fix to remove them")
+	}
+
+	if len(r.Dangling) > 0 {
+		if err := SetBootOrder(c, r.FixedOrder()); err != nil {
+			return fmt.Errorf("verify: %w", err)
+		}
+	}
+	if r.DanglingNext {
+		if err := ClearBootNext(c); err != nil {
+			return fmt.Errorf("verify: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2022 individual contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efibootctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+const (
+	// clearScreen moves the cursor home and clears the screen.
+	clearScreen = "\x1b[H\x1b[2J"
+
+	hideCursor = "\x1b[?25l"
+	showCursor = "\x1b[?25h"
+)
+
+func watchE(args []string) (err error) {
+	fs, out := newOutputFlagSet("watch")
+	defer multierr.AppendInvoke(&err, multierr.Close(out))
+	interval := fs.Duration("interval", 2*time.Second, "`duration` between reading the variables")
+	lo := newListOptions(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pol, err := lo.policy()
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	if *interval <= 0 {
+		return errors.New("watch: --interval must be positive")
+	}
+
+	c := out.openStore()
+	defer multierr.AppendInvoke(&err, multierr.Close(c))
+
+	render := func() (string, error) {
+		entries, bootOrder, err := lo.load(c, pol)
+		if err != nil {
+			return "", err
+		}
+		return out.renderTable(c, lo, entries, bootOrder)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// Hide the cursor while watching and make sure it is shown
+	// again on exit.
+	terminal := out.toTerminal()
+	if terminal {
+		_, _ = fmt.Fprint(out.writer(), hideCursor)
+		defer func() { _, _ = fmt.Fprint(out.writer(), showCursor) }()
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		// Only redraw if the state changed to avoid flicker.
+		frame, err := render()
+		if err != nil {
+			return fmt.Errorf("watch: %w", err)
+		}
+		if frame != last {
+			if terminal {
+				_, _ = fmt.Fprint(out.writer(), clearScreen)
+			}
+			_, _ = fmt.Fprint(out.writer(), frame)
+			last = frame
+		}
+
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
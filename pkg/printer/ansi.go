@@ -0,0 +1,96 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sgrPattern matches ANSI SGR escape sequences as written by
+// ColorizeText and ColorizeTextRGB.
+var sgrPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// StripANSI removes all SGR escape sequences from s.  Removing a
+// sequence may join the text around it into a new one, e.g. in
+// "\x1b[\x1b[0m31m", so it is repeated until none is left.
+func StripANSI(s string) string {
+	for sgrPattern.MatchString(s) {
+		s = sgrPattern.ReplaceAllString(s, "")
+	}
+	return s
+}
+
+// EscapeControl replaces the control characters in s, including
+// ESC, DEL and the C1 controls, and bytes which are not valid UTF-8
+// with Go escape sequences, so that text read from EFI variables
+// cannot emit escape sequences of its own when it is written to a
+// terminal.  All other characters are kept as they are.
+func EscapeControl(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			_, _ = fmt.Fprintf(&b, `\x%02x`, s[i])
+		case unicode.IsControl(r) && r < utf8.RuneSelf:
+			_, _ = fmt.Fprintf(&b, `\x%02x`, r)
+		case unicode.IsControl(r):
+			_, _ = fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// stripWriter removes SGR escape sequences from everything written
+// to it.  Escape sequences must not span several writes.
+type stripWriter struct {
+	w io.Writer
+}
+
+// NewStripWriter returns a writer removing SGR escape sequences
+// before writing to w.
+func NewStripWriter(w io.Writer) io.Writer {
+	return stripWriter{w}
+}
+
+func (s stripWriter) Write(b []byte) (int, error) {
+	stripped := b
+	for sgrPattern.Match(stripped) {
+		stripped = sgrPattern.ReplaceAll(stripped, nil)
+	}
+	if _, err := s.w.Write(stripped); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
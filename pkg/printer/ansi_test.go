@@ -0,0 +1,63 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "text", "text"},
+		{"colored", "\x1b[31mred\x1b[0m", "red"},
+		{"combined attributes", "\x1b[1;38;2;255;0;0mred\x1b[0m", "red"},
+		{"nested", "\x1b[\x1b[0m31mred", "red"},
+		{"deeply nested", "\x1b[\x1b[\x1b[0m1m31mred\x1b[0m", "red"},
+		{"other escapes are kept", "\x1b]0;title\x07", "\x1b]0;title\x07"},
+		{"incomplete", "\x1b[31", "\x1b[31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripANSI(tt.in); got != tt.want {
+				t.Errorf("StripANSI(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+
+			var buf bytes.Buffer
+			n, err := NewStripWriter(&buf).Write([]byte(tt.in))
+			if err != nil || n != len(tt.in) {
+				t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(tt.in))
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("stripWriter wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -88,6 +88,11 @@ var (
 		Time:            Blue | Bold,
 		StructName:      Green,
 		ObjectLength:    Blue,
+		Comment:         Black | Bold,
+		Added:           Green,
+		Removed:         Red,
+		Inactive:        Black | Bold,
+		GUID:            Cyan,
 	}
 )
 
@@ -107,6 +112,11 @@ const (
 	TimeColor
 	StructNameColor
 	ObjectLengthColor
+	CommentColor
+	AddedColor
+	RemovedColor
+	InactiveColor
+	GUIDColor
 )
 
 type ColorScheme struct {
@@ -122,6 +132,15 @@ type ColorScheme struct {
 	Time            uint16
 	StructName      uint16
 	ObjectLength    uint16
+	Comment         uint16
+	Added           uint16
+	Removed         uint16
+	Inactive        uint16
+	GUID            uint16
+
+	// RGB optionally overrides the foreground color of individual
+	// fields with a 24-bit color.
+	RGB map[ColorField]RGB
 }
 
 func (s ColorScheme) Get(field ColorField) uint16 {
@@ -150,10 +169,28 @@ func (s ColorScheme) Get(field ColorField) uint16 {
 		return s.StructName
 	case ObjectLengthColor:
 		return s.ObjectLength
+	case CommentColor:
+		return s.Comment
+	case AddedColor:
+		return s.Added
+	case RemovedColor:
+		return s.Removed
+	case InactiveColor:
+		return s.Inactive
+	case GUIDColor:
+		return s.GUID
 	}
 	panic("bad field value")
 }
 
+// Colorize colorizes text with the color of the given field.
+func (s ColorScheme) Colorize(text string, field ColorField) string {
+	if rgb, ok := s.RGB[field]; ok {
+		return ColorizeTextRGB(text, rgb, s.Get(field))
+	}
+	return ColorizeText(text, s.Get(field))
+}
+
 func ColorizeText(text string, color uint16) string {
 	foreground := color & maskForeground >> bitsForeground
 	background := color & maskBackground >> bitsBackground
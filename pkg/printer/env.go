@@ -0,0 +1,146 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvColorPrefix is the prefix of the environment variables
+// overriding individual fields of a ColorScheme.
+const EnvColorPrefix = "EFIBOOTCTL_COLOR_"
+
+var colorNames = map[string]uint16{
+	"black":   Black,
+	"red":     Red,
+	"green":   Green,
+	"yellow":  Yellow,
+	"blue":    Blue,
+	"magenta": Magenta,
+	"cyan":    Cyan,
+	"white":   White,
+}
+
+// parseColorSpec parses a color specification such as "green",
+// "magenta+bold+bg-white" or "#ff8800+bold" into the ColorScheme
+// encoding and an optional 24-bit foreground color.  "none"
+// disables the coloring of a field.
+func parseColorSpec(spec string) (color uint16, rgb *RGB, err error) {
+	for _, part := range strings.Split(strings.ToLower(spec), "+") {
+		part = strings.TrimSpace(part)
+
+		if strings.HasPrefix(part, "#") {
+			c, err := ParseRGB(part)
+			if err != nil {
+				return 0, nil, err
+			}
+			rgb = &c
+			continue
+		}
+
+		if strings.HasPrefix(part, "bg-") {
+			c, ok := colorNames[part[3:]]
+			if !ok {
+				return 0, nil, fmt.Errorf("unknown background color %q", part[3:])
+			}
+			color |= (c&maskForeground)<<bitsBackground | NoColor
+			continue
+		}
+
+		switch part {
+		case "none":
+			color |= NoColor
+		case "bold":
+			color |= Bold
+		default:
+			c, ok := colorNames[part]
+			if !ok {
+				return 0, nil, fmt.Errorf("unknown color %q", part)
+			}
+			color |= c
+		}
+	}
+	return color, rgb, nil
+}
+
+// SchemeFromEnv returns a copy of base with the fields overridden
+// by EFIBOOTCTL_COLOR_<FIELD> environment variables, for example
+// EFIBOOTCTL_COLOR_FIELDNAME=green or
+// EFIBOOTCTL_COLOR_INTEGER=#ff8800+bold.  Invalid values are
+// reported as warnings on stderr and leave the field of base in
+// place.
+func SchemeFromEnv(base *ColorScheme) *ColorScheme {
+	s := *base
+	s.RGB = make(map[ColorField]RGB, len(base.RGB))
+	for field, rgb := range base.RGB {
+		s.RGB[field] = rgb
+	}
+
+	fields := map[string]struct {
+		value *uint16
+		field ColorField
+	}{
+		"BOOL":            {&s.Bool, BoolColor},
+		"INTEGER":         {&s.Integer, IntegerColor},
+		"FLOAT":           {&s.Float, FloatColor},
+		"STRING":          {&s.String, StringColor},
+		"STRINGQUOTATION": {&s.StringQuotation, StringQuotationColor},
+		"ESCAPEDCHAR":     {&s.EscapedChar, EscapedCharColor},
+		"FIELDNAME":       {&s.FieldName, FieldNameColor},
+		"POINTERADRESS":   {&s.PointerAdress, PointerAdressColor},
+		"NIL":             {&s.Nil, NilColor},
+		"TIME":            {&s.Time, TimeColor},
+		"STRUCTNAME":      {&s.StructName, StructNameColor},
+		"OBJECTLENGTH":    {&s.ObjectLength, ObjectLengthColor},
+		"COMMENT":         {&s.Comment, CommentColor},
+		"ADDED":           {&s.Added, AddedColor},
+		"REMOVED":         {&s.Removed, RemovedColor},
+		"INACTIVE":        {&s.Inactive, InactiveColor},
+		"GUID":            {&s.GUID, GUIDColor},
+	}
+
+	for name, f := range fields {
+		spec, ok := os.LookupEnv(EnvColorPrefix + name)
+		if !ok {
+			continue
+		}
+
+		color, rgb, err := parseColorSpec(spec)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: %s%s: %s\n", EnvColorPrefix, name, err)
+			continue
+		}
+		*f.value = color
+		if rgb != nil {
+			s.RGB[f.field] = *rgb
+		} else {
+			delete(s.RGB, f.field)
+		}
+	}
+	return &s
+}
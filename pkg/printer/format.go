@@ -0,0 +1,39 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"io"
+)
+
+// FormatTo pretty prints object to w with a printer configured as
+// by NewPrinterWithOptions.  The width of w is measured if it is a
+// terminal, unless it is given with WithWidth.
+func FormatTo(w io.Writer, object any, opts ...Option) error {
+	p := NewPrinterWithOptions(object, append([]Option{WithWidth(writerWidth(w))}, opts...)...)
+	_, err := io.WriteString(w, p.Format(object))
+	return err
+}
@@ -0,0 +1,90 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// RegisterLabels registers translations of field labels, keyed by
+// their English text, for the given language.  Labels without a
+// translation are printed in English.
+func RegisterLabels(tag language.Tag, labels map[string]string) error {
+	for label, translation := range labels {
+		if err := message.SetString(tag, label, translation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LanguageFromEnv returns the language selected by the LC_ALL,
+// LC_MESSAGES or LANG environment variables, in that order, which
+// is English if none is set.
+func LanguageFromEnv() language.Tag {
+	return localeFromEnv("LC_ALL", "LC_MESSAGES", "LANG")
+}
+
+// numberLanguageFromEnv returns the language to format numbers in as
+// selected by the LC_ALL, LC_NUMERIC or LANG environment variables,
+// in that order, which is English if none is set.
+func numberLanguageFromEnv() language.Tag {
+	return localeFromEnv("LC_ALL", "LC_NUMERIC", "LANG")
+}
+
+// localeFromEnv returns the locale named by the first of the given
+// environment variables which is set.
+func localeFromEnv(names ...string) language.Tag {
+	for _, name := range names {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		return parseLocale(value)
+	}
+	return language.English
+}
+
+// parseLocale parses a POSIX locale name like "de_DE.UTF-8".
+func parseLocale(s string) language.Tag {
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	switch s {
+	case "", "C", "POSIX":
+		return language.English
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(s, "_", "-"))
+	if err != nil {
+		return language.English
+	}
+	return tag
+}
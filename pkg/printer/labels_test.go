@@ -0,0 +1,114 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+// setLocaleEnv sets the locale environment variables for the test,
+// unsetting the ones not given.
+func setLocaleEnv(t *testing.T, env map[string]string) {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LC_NUMERIC", "LANG"} {
+		t.Setenv(name, env[name])
+	}
+}
+
+func TestLocaleFromEnv(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantLanguage language.Tag
+		wantNumber   language.Tag
+	}{
+		{
+			name:         "unset",
+			wantLanguage: language.English,
+			wantNumber:   language.English,
+		},
+		{
+			name:         "LANG",
+			env:          map[string]string{"LANG": "de_DE.UTF-8"},
+			wantLanguage: language.MustParse("de-DE"),
+			wantNumber:   language.MustParse("de-DE"),
+		},
+		{
+			name:         "LC_NUMERIC overrides LANG for numbers",
+			env:          map[string]string{"LANG": "en_US.UTF-8", "LC_NUMERIC": "de_DE.UTF-8"},
+			wantLanguage: language.MustParse("en-US"),
+			wantNumber:   language.MustParse("de-DE"),
+		},
+		{
+			name:         "LC_MESSAGES overrides LANG for messages",
+			env:          map[string]string{"LANG": "en_US.UTF-8", "LC_MESSAGES": "de_DE.UTF-8"},
+			wantLanguage: language.MustParse("de-DE"),
+			wantNumber:   language.MustParse("en-US"),
+		},
+		{
+			name:         "LC_ALL overrides everything",
+			env:          map[string]string{"LC_ALL": "C", "LC_MESSAGES": "de_DE", "LC_NUMERIC": "de_DE", "LANG": "de_DE"},
+			wantLanguage: language.English,
+			wantNumber:   language.English,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLocaleEnv(t, tt.env)
+
+			if got := LanguageFromEnv(); got != tt.wantLanguage {
+				t.Errorf("LanguageFromEnv() = %v, want %v", got, tt.wantLanguage)
+			}
+			if got := numberLanguageFromEnv(); got != tt.wantNumber {
+				t.Errorf("numberLanguageFromEnv() = %v, want %v", got, tt.wantNumber)
+			}
+		})
+	}
+}
+
+func TestThousandsSeparatorFollowsNumberLocale(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"english", nil, "1,234,567"},
+		{"german", map[string]string{"LC_NUMERIC": "de_DE.UTF-8"}, "1.234.567"},
+		{"german messages only", map[string]string{"LC_MESSAGES": "de_DE.UTF-8"}, "1,234,567"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLocaleEnv(t, tt.env)
+
+			got := NewPrinterWithOptions(nil).Format(1234567)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Format(1234567) = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ColorMode selects when output is colorized.
+type ColorMode int
+
+const (
+	// ColorAuto colorizes the output only if standard output is
+	// a terminal and NO_COLOR is not set.
+	ColorAuto ColorMode = iota
+
+	// ColorNever disables colorization.
+	ColorNever
+
+	// ColorAlways colorizes the output unconditionally.
+	ColorAlways
+)
+
+var colorModeNames = map[ColorMode]string{
+	ColorAuto:   "auto",
+	ColorNever:  "never",
+	ColorAlways: "always",
+}
+
+func (m ColorMode) String() string {
+	if name, ok := colorModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("ColorMode(%d)", int(m))
+}
+
+// Set implements flag.Value.
+func (m *ColorMode) Set(s string) error {
+	for mode, name := range colorModeNames {
+		if name == s {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid color mode %q, expected never, auto or always", s)
+}
+
+// NoColorRequested reports whether the user asked for colorless
+// output by setting the NO_COLOR environment variable.
+//
+// See <https://no-color.org/>.
+func NoColorRequested() bool {
+	_, ok := os.LookupEnv("NO_COLOR")
+	return ok
+}
+
+// IsTerminal reports whether the given file is a terminal.
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// stdoutIsTerminal reports whether standard output is a terminal.
+// It is a variable, so that tests can pretend to write to one.
+var stdoutIsTerminal = func() bool { return IsTerminal(os.Stdout) }
+
+// Scheme returns the color scheme to pass to NewPrinter for output
+// written to standard output, which is nil when colors are off.
+func (m ColorMode) Scheme(s *ColorScheme) *ColorScheme {
+	switch m {
+	case ColorNever:
+		return nil
+	case ColorAlways:
+		return s
+	}
+
+	if NoColorRequested() || !stdoutIsTerminal() {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorModeScheme(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ColorMode
+		noColor  bool
+		terminal bool
+		want     bool
+	}{
+		{"auto on a terminal", ColorAuto, false, true, true},
+		{"auto with NO_COLOR", ColorAuto, true, true, false},
+		{"auto without a terminal", ColorAuto, false, false, false},
+		{"always with NO_COLOR", ColorAlways, true, false, true},
+		{"never", ColorNever, false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "1")
+			if !tt.noColor {
+				_ = os.Unsetenv("NO_COLOR")
+			}
+			saved := stdoutIsTerminal
+			stdoutIsTerminal = func() bool { return tt.terminal }
+			t.Cleanup(func() { stdoutIsTerminal = saved })
+
+			scheme := tt.mode.Scheme(DefaultScheme)
+			if got := scheme != nil; got != tt.want {
+				t.Fatalf("Scheme() returned a scheme = %v, want %v", got, tt.want)
+			}
+
+			out := NewPrinter("", scheme, true, true, true).Format(struct{ A string }{"text"})
+			if got := strings.Contains(out, "\x1b["); got != tt.want {
+				t.Errorf("output contains escape sequences = %v, want %v: %q", got, tt.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+// Option configures a printer created with NewPrinterWithOptions
+// or used by FormatTo.
+type Option func(p *Printer)
+
+// WithColorScheme colorizes the output with the given scheme.
+func WithColorScheme(s *ColorScheme) Option {
+	return func(p *Printer) { p.colorScheme = s }
+}
+
+// WithFoldThreshold overrides the DefaultFoldThreshold, see
+// SetFoldThreshold.
+func WithFoldThreshold(n int) Option {
+	return func(p *Printer) { p.SetFoldThreshold(n) }
+}
+
+// WithMaxDepth limits the nesting depth printed, see SetMaxDepth.
+func WithMaxDepth(n int) Option {
+	return func(p *Printer) { p.SetMaxDepth(n) }
+}
+
+// WithSortMaps sorts the entries of maps by their keys, which is
+// the default.  Otherwise maps are printed in the order returned by
+// reflect.Value.MapKeys.
+func WithSortMaps(enabled bool) Option {
+	return func(p *Printer) { p.sortMaps = enabled }
+}
+
+// WithBytesAsString prints byte slices holding printable UTF-8
+// text as quoted strings instead of groups of bytes.
+func WithBytesAsString(enabled bool) Option {
+	return func(p *Printer) { p.bytesAsString = enabled }
+}
+
+// WithOmitZero omits all struct fields holding their zero value,
+// as if every field was tagged with `pp:",omitempty"`.
+func WithOmitZero(enabled bool) Option {
+	return func(p *Printer) { p.omitZero = enabled }
+}
+
+// WithIndentWidth sets the minimal width of an indentation level
+// and of the field name column, DefaultIndentWidth by default.
+func WithIndentWidth(n int) Option {
+	return func(p *Printer) { p.indentWidth = n }
+}
+
+// WithPadding sets the number of spaces added to the width of every
+// column, DefaultPadding by default.
+func WithPadding(n int) Option {
+	return func(p *Printer) { p.padding = n }
+}
+
+// WithWidth sets the width of the terminal the output is written
+// to, see SetWidth.
+func WithWidth(n int) Option {
+	return func(p *Printer) { p.SetWidth(n) }
+}
+
+// WithDecimalUint prints unsigned integers in decimal instead of
+// hexadecimal notation.
+func WithDecimalUint(enabled bool) Option {
+	return func(p *Printer) { p.decimalUint = enabled }
+}
+
+// WithExportedOnly omits the unexported fields of structs.
+func WithExportedOnly(enabled bool) Option {
+	return func(p *Printer) { p.exportedOnly = enabled }
+}
+
+// WithThousandsSeparator groups the digits of numbers according
+// to the language selected by the environment.
+func WithThousandsSeparator(enabled bool) Option {
+	return func(p *Printer) { p.thousandsSeparator = enabled }
+}
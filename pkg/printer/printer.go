@@ -34,8 +34,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/mattn/go-colorable"
 	"golang.org/x/text/language"
@@ -43,7 +46,21 @@ import (
 )
 
 const (
-	indentWidth = 4
+	// DefaultIndentWidth is the minimal width of an indentation
+	// level and of the field name column.
+	DefaultIndentWidth = 4
+
+	// DefaultPadding is the number of spaces added to the width
+	// of every column.
+	DefaultPadding = 1
+
+	// DefaultFoldThreshold is the number of elements above which
+	// slices and arrays are folded to their head and tail.
+	DefaultFoldThreshold = 1024
+
+	// foldEdge is the number of elements kept at either end of a
+	// folded slice whose elements are not printed in groups.
+	foldEdge = 4
 )
 
 var (
@@ -57,24 +74,41 @@ func NewPrinter(
 	exportedOnly bool,
 	thousandsSeparator bool,
 ) *Printer {
-	buffer := bytes.NewBufferString("")
-	tw := new(tabwriter.Writer)
-	tw.Init(buffer, indentWidth, 0, 1, ' ', 0)
+	return NewPrinterWithOptions(
+		object,
+		WithColorScheme(colorScheme),
+		WithDecimalUint(decimalUint),
+		WithExportedOnly(exportedOnly),
+		WithThousandsSeparator(thousandsSeparator),
+	)
+}
 
+// NewPrinterWithOptions returns a printer for object configured by
+// the given options.  Without options the object is printed without
+// colors, with decimal unsigned integers, thousands separators and
+// only the exported struct fields.
+func NewPrinterWithOptions(object interface{}, opts ...Option) *Printer {
 	printer := &Printer{
-		Buffer:             buffer,
-		tw:                 tw,
+		Buffer:             bytes.NewBufferString(""),
+		tw:                 new(tabwriter.Writer),
 		depth:              0,
 		value:              reflect.ValueOf(object),
 		visited:            map[uintptr]bool{},
-		colorScheme:        colorScheme,
-		decimalUint:        decimalUint,
-		exportedOnly:       exportedOnly,
-		thousandsSeparator: thousandsSeparator,
+		decimalUint:        true,
+		exportedOnly:       true,
+		thousandsSeparator: true,
+		foldThreshold:      DefaultFoldThreshold,
+		sortMaps:           true,
+		indentWidth:        DefaultIndentWidth,
+		padding:            DefaultPadding,
+	}
+	for _, opt := range opts {
+		opt(printer)
 	}
+	printer.initTabWriter()
 
-	if thousandsSeparator {
-		printer.localizedPrinter = message.NewPrinter(language.English)
+	if printer.thousandsSeparator {
+		printer.localizedPrinter = message.NewPrinter(numberLanguageFromEnv())
 	}
 
 	return printer
@@ -91,8 +125,61 @@ type Printer struct {
 	exportedOnly       bool
 	thousandsSeparator bool
 	localizedPrinter   *message.Printer
+	foldThreshold      int
+	showNotes          bool
+	labelPrinter       *message.Printer
+	maxDepth           int
+	sortMaps           bool
+	bytesAsString      bool
+	omitZero           bool
+	indentWidth        int
+	padding            int
+	width              int
 }
 
+// initTabWriter sets up the tabwriter aligning the output with the
+// configured indent width and padding.  It must be called again
+// whenever these change before anything is printed.
+func (p *Printer) initTabWriter() {
+	p.tw.Init(p.Buffer, p.indentWidth, 0, p.padding, ' ', 0)
+}
+
+// SetWidth sets the number of columns of the terminal the output is
+// written to, which lets byte slices use the full width.  A width of
+// 0, the default, means the output is not a terminal.
+func (p *Printer) SetWidth(n int) { p.width = n }
+
+// SetFoldThreshold sets the number of elements above which slices
+// and arrays are folded: only the first and the last group of
+// elements are printed along with the number of elements left out.
+// A threshold of 0 disables folding.
+func (p *Printer) SetFoldThreshold(n int) { p.foldThreshold = n }
+
+// SetMaxDepth sets the nesting depth below which structs, maps,
+// slices and pointers are elided as "{...}".  A depth of 0, the
+// default, disables the limit.
+func (p *Printer) SetMaxDepth(n int) { p.maxDepth = n }
+
+// tooDeep reports whether the depth limit set with SetMaxDepth is
+// reached.
+func (p *Printer) tooDeep() bool { return p.maxDepth > 0 && p.depth >= p.maxDepth }
+
+// SetLanguage makes PrintFieldValue translate field labels into
+// the given language.  See RegisterLabels.
+func (p *Printer) SetLanguage(tag language.Tag) { p.labelPrinter = message.NewPrinter(tag) }
+
+// label returns the translation of the given field label.
+func (p *Printer) label(k string) string {
+	if p.labelPrinter == nil {
+		return k
+	}
+	return p.labelPrinter.Sprintf(message.Key(k, strings.ReplaceAll(k, "%", "%%")))
+}
+
+// SetShowNotes makes PrintFieldValueWithNote print notes even if
+// coloring is disabled.
+func (p *Printer) SetShowNotes(show bool) { p.showNotes = show }
+
 func (p *Printer) String() string {
 	p.tw.Flush()
 	return p.Buffer.String()
@@ -119,7 +206,38 @@ func (p *Printer) PrintKeyValue(k, v any) {
 }
 
 func (p *Printer) PrintFieldValue(k string, v any) {
-	colorizedFieldName := p.Colorize(k, FieldNameColor)
+	colorizedFieldName := p.Colorize(p.label(k), FieldNameColor)
+	p.IndentPrintf("%s:\t%s\n", colorizedFieldName, p.Format(v))
+}
+
+// PrintFieldValueWithNote prints a field like PrintFieldValue
+// followed by a trailing comment.  The comment is only printed if
+// coloring is enabled or notes were requested with SetShowNotes.
+func (p *Printer) PrintFieldValueWithNote(k string, v any, note string) {
+	if note == "" || !(p.IsColoringEnabled() || p.showNotes) {
+		p.PrintFieldValue(k, v)
+		return
+	}
+
+	colorizedFieldName := p.Colorize(p.label(k), FieldNameColor)
+	colorizedNote := p.Colorize("# "+note, CommentColor)
+	p.IndentPrintf("%s:\t%s\t%s\n", colorizedFieldName, p.Format(v), colorizedNote)
+}
+
+// PrintFieldAdded prints a field like PrintFieldValue marked as
+// added with a leading "+".
+func (p *Printer) PrintFieldAdded(k string, v any) {
+	p.printMarkedField("+", AddedColor, k, v)
+}
+
+// PrintFieldRemoved prints a field like PrintFieldValue marked as
+// removed with a leading "-".
+func (p *Printer) PrintFieldRemoved(k string, v any) {
+	p.printMarkedField("-", RemovedColor, k, v)
+}
+
+func (p *Printer) printMarkedField(marker string, color ColorField, k string, v any) {
+	colorizedFieldName := p.Colorize(marker+" "+p.label(k), color)
 	p.IndentPrintf("%s:\t%s\n", colorizedFieldName, p.Format(v))
 }
 
@@ -128,6 +246,14 @@ func (p *Printer) printString() {
 	quoted = quoted[1 : len(quoted)-1]
 
 	p.ColorPrint(`"`, StringQuotationColor)
+	p.printQuoted(quoted)
+	p.ColorPrint(`"`, StringQuotationColor)
+}
+
+// printQuoted prints the body of a quoted string, highlighting its
+// escape sequences.  A lone backslash or an escape sequence cut
+// short at the end is printed literally with the rest of the text.
+func (p *Printer) printQuoted(quoted string) {
 	for len(quoted) > 0 {
 		pos := strings.IndexByte(quoted, '\\')
 		if pos == -1 {
@@ -137,6 +263,10 @@ func (p *Printer) printString() {
 		if pos != 0 {
 			p.ColorPrint(quoted[0:pos], StringColor)
 		}
+		if pos+1 >= len(quoted) {
+			p.ColorPrint(quoted[pos:], StringColor)
+			break
+		}
 
 		n := 1
 		switch quoted[pos+1] {
@@ -149,19 +279,26 @@ func (p *Printer) printString() {
 		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9': // "\000"
 			n = 3
 		}
+		if pos+n+1 > len(quoted) {
+			p.ColorPrint(quoted[pos:], StringColor)
+			break
+		}
 		p.ColorPrint(quoted[pos:pos+n+1], EscapedCharColor)
 		quoted = quoted[pos+n+1:]
 	}
-	p.ColorPrint(`"`, StringQuotationColor)
 }
 
 func (p *Printer) printMap() {
+	if p.value.IsNil() {
+		p.Printf("%s(%s)", p.typeString(), p.nil())
+		return
+	}
 	if p.value.Len() == 0 {
 		p.Printf("%s{}", p.typeString())
 		return
 	}
 
-	if p.visited[p.value.Pointer()] {
+	if p.visited[p.value.Pointer()] || p.tooDeep() {
 		p.Printf("%s{...}", p.typeString())
 		return
 	}
@@ -170,7 +307,12 @@ func (p *Printer) printMap() {
 	p.Printf("%s{\n", p.typeString())
 
 	p.indented(func() {
-		value := sortMap(p.value)
+		var value *sortedMap
+		if p.sortMaps {
+			value = sortMap(p.value)
+		} else {
+			value = mapEntries(p.value)
+		}
 		for i := 0; i < value.Len(); i++ {
 			p.IndentPrintf("%s:\t%s,\n", p.Format(value.keys[i]), p.Format(value.values[i]))
 		}
@@ -202,6 +344,9 @@ func (p *Printer) printStruct() {
 		if p.exportedOnly && field.PkgPath != "" {
 			continue
 		}
+		if p.omitZero && valueIsZero(value) {
+			continue
+		}
 		// ignore fields if zero value, or explicitly set
 		if tag := field.Tag.Get("pp"); tag != "" {
 			parts := strings.Split(tag, ",")
@@ -219,6 +364,10 @@ func (p *Printer) printStruct() {
 		p.Print(p.typeString() + "{}")
 		return
 	}
+	if p.tooDeep() {
+		p.Print(p.typeString() + "{...}")
+		return
+	}
 
 	p.Println(p.typeString() + "{")
 	p.indented(func() {
@@ -264,6 +413,16 @@ func (p *Printer) printSlice() {
 		p.Printf("%s{}", p.typeString())
 		return
 	}
+	if p.bytesAsString && p.value.Kind() == reflect.Slice && p.value.Type().Elem().Kind() == reflect.Uint8 {
+		if b := p.value.Bytes(); isPrintableText(b) {
+			p.Print(p.Format(string(b)))
+			return
+		}
+	}
+	if p.tooDeep() {
+		p.Printf("%s{...}", p.typeString())
+		return
+	}
 
 	if p.value.Kind() == reflect.Slice {
 		if p.visited[p.value.Pointer()] {
@@ -274,16 +433,10 @@ func (p *Printer) printSlice() {
 		p.visited[p.value.Pointer()] = true
 	}
 
-	// Fold a large buffer
-	if p.value.Len() > 1024 {
-		p.Printf("%s{...}", p.typeString())
-		return
-	}
-
 	var groupSize int
 	switch p.value.Type().Elem().Kind() {
 	case reflect.Uint8:
-		groupSize = 16
+		groupSize = p.byteGroupSize()
 	case reflect.Uint16:
 		groupSize = 8
 	case reflect.Uint32:
@@ -294,7 +447,29 @@ func (p *Printer) printSlice() {
 		groupSize = 36 / stringGroupSize(p.value.Interface())
 	}
 
-	if p.value.Len() < groupSize {
+	// Fold a large buffer, keeping the elements before head and
+	// from tail on.
+	head, tail := p.value.Len(), p.value.Len()
+	if p.foldThreshold > 0 && p.value.Len() > p.foldThreshold {
+		head = foldEdge
+		if groupSize > 0 {
+			head = groupSize
+		}
+		tail = p.value.Len() - head
+		if groupSize > 0 {
+			// Start the tail at a group boundary.
+			tail = (tail + groupSize - 1) / groupSize * groupSize
+		}
+		if tail <= head {
+			// Nothing is left to elide.
+			head, tail = p.value.Len(), p.value.Len()
+		}
+	}
+	elided := func() string {
+		return p.Colorize(fmt.Sprintf("// %d elements elided", tail-head), CommentColor)
+	}
+
+	if p.value.Len() < groupSize && head == tail {
 		p.Print("{")
 		p.Printf("%s", p.Format(p.value.Index(0)))
 		for i := 1; i < p.value.Len(); i++ {
@@ -303,12 +478,31 @@ func (p *Printer) printSlice() {
 		p.Print("}")
 	} else {
 		p.Println("{")
+		// Prefix groups of bytes with their offset unless they
+		// fit on a single line.
+		offsetFormat := ""
+		if p.value.Type().Elem().Kind() == reflect.Uint8 && p.value.Len() > groupSize {
+			digits := len(strconv.FormatInt(int64(p.value.Len()-1), 16))
+			if digits < 4 {
+				digits = 4
+			}
+			offsetFormat = fmt.Sprintf("%%0%dx:", digits)
+		}
+
 		p.indented(func() {
 			if groupSize > 0 {
 				for i := 0; i < p.value.Len(); i++ {
+					if i == head && head < tail {
+						p.IndentPrintf("%s\n", elided())
+						i = tail - 1
+						continue
+					}
 					// Indent for new group
 					if i%groupSize == 0 {
 						p.Print(p.Indent())
+						if offsetFormat != "" {
+							p.Print(p.Colorize(fmt.Sprintf(offsetFormat, i), CommentColor) + " ")
+						}
 					}
 					// slice element
 					p.Printf("%s,", p.Format(p.value.Index(i)))
@@ -321,6 +515,11 @@ func (p *Printer) printSlice() {
 				}
 			} else {
 				for i := 0; i < p.value.Len(); i++ {
+					if i == head && head < tail {
+						p.IndentPrintf("%s\n", elided())
+						i = tail - 1
+						continue
+					}
 					p.IndentPrintf("%s,\n", p.Format(p.value.Index(i)))
 				}
 			}
@@ -329,6 +528,20 @@ func (p *Printer) printSlice() {
 	}
 }
 
+// isPrintableText reports whether b is valid UTF-8 consisting of
+// printable characters and whitespace only.
+func isPrintableText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
 func stringGroupSize(i any) (max int) {
 	for _, s := range i.([]string) {
 		if l := len(s); l > max {
@@ -340,17 +553,30 @@ func stringGroupSize(i any) (max int) {
 
 func (p *Printer) printInterface() {
 	e := p.value.Elem()
-	if e.Kind() == reflect.Invalid {
+	switch {
+	case !e.IsValid():
+		// The interface holds no value at all.
 		p.Print(p.nil())
-	} else if e.IsValid() {
+	case isTypedNil(e):
+		// Print typed nils with their type as "%#v" does.
+		p.Printf("(%s)(%s)", p.colorizeType(e.Type().String()), p.nil())
+	default:
 		p.Print(p.Format(e))
-	} else {
-		p.Printf("%s(%s)", p.typeString(), p.nil())
 	}
 }
 
+// isTypedNil reports whether v is a nil pointer, func or channel.
+// Nil maps and slices are left to printMap and printSlice.
+func isTypedNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
 func (p *Printer) printPtr() {
-	if p.visited[p.value.Pointer()] {
+	if p.visited[p.value.Pointer()] || (p.tooDeep() && p.value.Pointer() != 0) {
 		p.Printf("&%s{...}", p.elemTypeString())
 		return
 	}
@@ -472,21 +698,57 @@ func (p *Printer) nil() string {
 
 func (p *Printer) Colorize(text string, color ColorField) string {
 	if p.IsColoringEnabled() {
-		return ColorizeText(text, p.colorScheme.Get(color))
+		return p.colorScheme.Colorize(text, color)
 	} else {
 		return text
 	}
 }
 
-func (p *Printer) Format(object interface{}) string {
-	pp := NewPrinter(object, p.colorScheme, p.decimalUint, p.exportedOnly, p.thousandsSeparator)
+// maxPooledBufferSize is the capacity above which the buffer of a
+// nested printer is not kept for reuse.
+const maxPooledBufferSize = 64 << 10
+
+// printerPool holds the printers used by Format for nested values,
+// so that their buffers and tabwriters can be reused.
+var printerPool = sync.Pool{
+	New: func() any {
+		return &Printer{Buffer: new(bytes.Buffer), tw: new(tabwriter.Writer)}
+	},
+}
+
+// nested returns a printer from the pool formatting object with the
+// configuration, depth and visited pointers of p.
+func (p *Printer) nested(object interface{}) *Printer {
+	pp := printerPool.Get().(*Printer)
+	buffer, tw := pp.Buffer, pp.tw
+	buffer.Reset()
+
+	*pp = *p
+	pp.Buffer, pp.tw = buffer, tw
+	pp.value = reflect.ValueOf(object)
 	if value, ok := object.(reflect.Value); ok {
 		pp.value = value
 	}
-	pp.depth = p.depth
-	pp.visited = p.visited
+	pp.initTabWriter()
+	return pp
+}
+
+// release returns a printer obtained from nested to the pool.  Its
+// configuration is cleared, so that the pool does not keep the
+// values and printers of the parent alive.
+func (p *Printer) release() {
+	if p.Buffer.Cap() > maxPooledBufferSize {
+		return
+	}
+	*p = Printer{Buffer: p.Buffer, tw: p.tw}
+	printerPool.Put(p)
+}
 
-	if f, ok := pp.value.Interface().(interface{ PrettyPrint(*Printer) }); ok {
+func (p *Printer) Format(object interface{}) string {
+	pp := p.nested(object)
+	defer pp.release()
+
+	if f, ok := prettyPrinter(pp.value); ok {
 		f.PrettyPrint(pp)
 	} else {
 		switch pp.value.Kind() {
@@ -525,6 +787,15 @@ func (p *Printer) Format(object interface{}) string {
 	return pp.String()
 }
 
+// prettyPrinter returns the PrettyPrint implementation of v, if any.
+func prettyPrinter(v reflect.Value) (interface{ PrettyPrint(*Printer) }, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	f, ok := v.Interface().(interface{ PrettyPrint(*Printer) })
+	return f, ok
+}
+
 func (p *Printer) Indent() string {
 	return strings.Repeat("\t", p.depth)
 }
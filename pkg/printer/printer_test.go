@@ -0,0 +1,316 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+// notedField prints a field with a note through the printer passed
+// to PrettyPrint.
+type notedField struct{}
+
+func (notedField) PrettyPrint(p *Printer) {
+	p.PrintFieldValueWithNote("Inner", 1, "a note")
+}
+
+func TestFormatNestedKeepsConfiguration(t *testing.T) {
+	p := NewPrinter("", nil, true, true, true)
+	p.SetShowNotes(true)
+	p.PrintFieldValue("Outer", notedField{})
+
+	if got := p.String(); !strings.Contains(got, "# a note") {
+		t.Errorf("nested printer dropped the note:\n%s", got)
+	}
+}
+
+func TestFormatStringKeepsReplacementCharacter(t *testing.T) {
+	got := NewPrinter("", nil, true, true, true).Format("a\ufffdb")
+	if want := "\"a\ufffdb\""; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestPrintQuoted(t *testing.T) {
+	scheme := &ColorScheme{String: Green, EscapedChar: Red}
+	str := func(s string) string { return scheme.Colorize(s, StringColor) }
+	esc := func(s string) string { return scheme.Colorize(s, EscapedCharColor) }
+
+	tests := []struct {
+		name   string
+		quoted string
+		want   string
+	}{
+		{"plain", `abc`, str(`abc`)},
+		{"newline", `a\nb`, str(`a`) + esc(`\n`) + str(`b`)},
+		{"escaped backslash", `a\\b`, str(`a`) + esc(`\\`) + str(`b`)},
+		{"hex", `\x1b[0m`, esc(`\x1b`) + str(`[0m`)},
+		{"short unicode", `\u00e9x`, esc(`\u00e9`) + str(`x`)},
+		{"long unicode", `\U0001f600`, esc(`\U0001f600`)},
+		{"octal", `\000`, esc(`\000`)},
+		{"lone backslash", `\`, str(`\`)},
+		{"trailing backslash", `ab\`, str(`ab`) + str(`\`)},
+		{"truncated hex", `a\x1`, str(`a`) + str(`\x1`)},
+		{"hex without digits", `a\x`, str(`a`) + str(`\x`)},
+		{"truncated short unicode", `\u12`, str(`\u12`)},
+		{"truncated long unicode", `\U0001f6`, str(`\U0001f6`)},
+		{"truncated octal", `\0`, str(`\0`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPrinter("", scheme, true, true, true)
+			p.printQuoted(tt.quoted)
+			if got := p.String(); got != tt.want {
+				t.Errorf("printQuoted(%q) = %q, want %q", tt.quoted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFoldsLargeSlices(t *testing.T) {
+	bytes := make([]byte, 2000)
+	ints := make([]int, 2000)
+	for i := range bytes {
+		bytes[i] = byte(i)
+		ints[i] = i
+	}
+
+	tests := []struct {
+		name      string
+		value     any
+		threshold int
+		want      []string
+		wantNot   []string
+	}{
+		{
+			name:      "bytes",
+			value:     bytes,
+			threshold: DefaultFoldThreshold,
+			want: []string{
+				"0000: 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,\n",
+				"// 1968 elements elided\n",
+				"07c0: 192, 193, 194, 195, 196, 197, 198, 199, 200, 201, 202, 203, 204, 205, 206, 207,\n",
+			},
+			wantNot: []string{"0010:", "07b0:"},
+		},
+		{
+			name:      "ints",
+			value:     ints,
+			threshold: DefaultFoldThreshold,
+			want:      []string{"{\n    0,\n    1,\n    2,\n    3,\n    // 1992 elements elided\n    1996,\n    1997,\n    1998,\n    1999,\n}"},
+			wantNot:   []string{"    4,\n", "1995"},
+		},
+		{
+			name:      "below the threshold",
+			value:     ints[:10],
+			threshold: 10,
+			want:      []string{"    9,\n"},
+			wantNot:   []string{"elided"},
+		},
+		{
+			name:      "nothing to elide",
+			value:     bytes[:20],
+			threshold: 10,
+			want:      []string{"0010: 16, 17, 18, 19,\n"},
+			wantNot:   []string{"elided"},
+		},
+		{
+			name:      "folding disabled",
+			value:     bytes,
+			threshold: 0,
+			want:      []string{"0000: 0,", "03e0: 224,", "07c0: 192,"},
+			wantNot:   []string{"elided"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPrinterWithOptions(nil, WithFoldThreshold(tt.threshold), WithThousandsSeparator(false)).Format(tt.value)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Format() is missing %q:\n%s", want, got)
+				}
+			}
+			for _, want := range tt.wantNot {
+				if strings.Contains(got, want) {
+					t.Errorf("Format() contains %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+
+	// Without folding every element is printed.
+	got := NewPrinterWithOptions(nil, WithFoldThreshold(0)).Format(ints)
+	if n := strings.Count(got, ",\n"); n != len(ints) {
+		t.Errorf("Format() printed %d elements, want %d", n, len(ints))
+	}
+}
+
+// depthNode is a link in the chain of structs printed by
+// TestFormatMaxDepth.
+type depthNode struct {
+	Level int
+	Child *depthNode
+}
+
+func TestFormatMaxDepth(t *testing.T) {
+	var chain *depthNode
+	for i := 9; i >= 0; i-- {
+		chain = &depthNode{Level: i, Child: chain}
+	}
+
+	tests := []struct {
+		name     string
+		value    any
+		maxDepth int
+		want     string
+	}{
+		{
+			name:     "struct",
+			value:    chain,
+			maxDepth: 3,
+			want: `&printer.depthNode{
+    Level: 0,
+    Child: &printer.depthNode{
+        Level: 1,
+        Child: &printer.depthNode{
+            Level: 2,
+            Child: &printer.depthNode{...},
+        },
+    },
+}`,
+		},
+		{
+			name:     "slice",
+			value:    [][][][]int{{{{1}}}},
+			maxDepth: 3,
+			want: `{
+    {
+        {
+            []int{...},
+        },
+    },
+}`,
+		},
+		{
+			name:     "map",
+			value:    map[string]any{"a": map[string]any{"b": map[string]any{"c": map[string]int{"d": 1}}}},
+			maxDepth: 3,
+			want: `map[string]interface {}{
+    "a": map[string]interface {}{
+        "b": map[string]interface {}{
+            "c": map[string]int{...},
+        },
+    },
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPrinterWithOptions(nil, WithMaxDepth(tt.maxDepth)).Format(tt.value)
+			if got != tt.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// Without a limit the whole chain is printed.
+	got := NewPrinterWithOptions(nil).Format(chain)
+	if !strings.Contains(got, "Level: 9,") || strings.Contains(got, "{...}") {
+		t.Errorf("Format() without a depth limit =\n%s", got)
+	}
+}
+
+// nilHolder holds nil values of different kinds.
+type nilHolder struct {
+	Interface any
+	Error     error
+	Map       map[string]int
+	Slice     []int
+	Pointer   *depthNode
+}
+
+func TestFormatNil(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"untyped nil", nil, "nil"},
+		{"nil map", map[string]int(nil), "map[string]int(nil)"},
+		{"empty map", map[string]int{}, "map[string]int{}"},
+		{"nil slice", []int(nil), "[]int(nil)"},
+		{"empty slice", []int{}, "[]int{}"},
+		{"typed nil pointer", (*depthNode)(nil), "(*printer.depthNode)(nil)"},
+		{"non-nil interface", []any{1}, "{\n    1,\n}"},
+		{
+			name:  "nil values in an interface slice",
+			value: []any{nil, map[string]int(nil), []int(nil), (*depthNode)(nil)},
+			want: `{
+    nil,
+    map[string]int(nil),
+    []int(nil),
+    (*printer.depthNode)(nil),
+}`,
+		},
+		{
+			name:  "nil fields",
+			value: nilHolder{},
+			want: `printer.nilHolder{
+    Interface: nil,
+    Error:     nil,
+    Map:       map[string]int(nil),
+    Slice:     []int(nil),
+    Pointer:   (*printer.depthNode)(nil),
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPrinterWithOptions(nil).Format(tt.value); got != tt.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// benchElem is an element of the slice formatted by BenchmarkFormat.
+type benchElem struct {
+	Index int
+	Name  string
+}
+
+func BenchmarkFormat(b *testing.B) {
+	elems := make([]benchElem, 1000)
+	for i := range elems {
+		elems[i] = benchElem{Index: i, Name: "entry"}
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewPrinterWithOptions(nil).Format(elems)
+	}
+}
@@ -40,40 +40,168 @@ func (s *sortedMap) Swap(i, j int) {
 }
 
 func (s *sortedMap) Less(i, j int) bool {
-	a, b := s.keys[i], s.keys[j]
+	return compareValues(s.keys[i], s.keys[j]) < 0
+}
+
+// pointerPair is a pair of pointers compared by compareValues.
+type pointerPair struct{ a, b uintptr }
+
+// compareValues defines a deterministic total order on map keys.
+// It returns a negative number if a sorts before b, a positive
+// number if b sorts before a and zero otherwise.  Values of
+// different types are ordered by the names of their types.
+func compareValues(a, b reflect.Value) int {
+	return compareValuesSeen(a, b, nil)
+}
+
+// compareValuesSeen implements compareValues.  seen holds the pairs
+// of pointers being compared further up, so that cyclic values are
+// ordered by their addresses instead of being followed forever.
+func compareValuesSeen(a, b reflect.Value, seen map[pointerPair]bool) int {
+	if !a.IsValid() || !b.IsValid() {
+		return compareBools(a.IsValid(), b.IsValid())
+	}
+
+	if a.Kind() == reflect.Interface || b.Kind() == reflect.Interface {
+		return compareValuesSeen(elem(a), elem(b), seen)
+	}
+
 	if a.Type() != b.Type() {
-		return false // give up
+		return compareStrings(typeName(a.Type()), typeName(b.Type()))
 	}
 
-	// Return true if b is bigger
 	switch a.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return a.Int() < b.Int()
+		return compareOrdered(a.Int(), b.Int())
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return a.Uint() < b.Uint()
+		return compareOrdered(a.Uint(), b.Uint())
 	case reflect.String:
-		return a.String() < b.String()
+		return compareStrings(a.String(), b.String())
 	case reflect.Float32, reflect.Float64:
-		if a.Float() != a.Float() || b.Float() != b.Float() {
-			return false // NaN
+		return compareFloats(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := compareFloats(real(a.Complex()), real(b.Complex())); c != 0 {
+			return c
 		}
-		return a.Float() < b.Float()
+		return compareFloats(imag(a.Complex()), imag(b.Complex()))
 	case reflect.Bool:
-		return !a.Bool() && b.Bool()
+		return compareBools(a.Bool(), b.Bool())
 	case reflect.Ptr:
-		return a.Pointer() < b.Pointer()
+		// Order by the pointed to values first so that the order
+		// does not depend on memory addresses if possible.
+		if a.IsNil() || b.IsNil() {
+			return compareBools(!a.IsNil(), !b.IsNil())
+		}
+		pair := pointerPair{a.Pointer(), b.Pointer()}
+		if pair.a == pair.b {
+			return 0
+		}
+		if !seen[pair] {
+			if seen == nil {
+				seen = map[pointerPair]bool{}
+			}
+			seen[pair] = true
+			c := compareValuesSeen(a.Elem(), b.Elem(), seen)
+			delete(seen, pair)
+			if c != 0 {
+				return c
+			}
+		}
+		return compareOrdered(pair.a, pair.b)
+	case reflect.Chan, reflect.UnsafePointer:
+		return compareOrdered(a.Pointer(), b.Pointer())
 	case reflect.Struct:
-		return a.NumField() < b.NumField()
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareValuesSeen(a.Field(i), b.Field(i), seen); c != 0 {
+				return c
+			}
+		}
+		return 0
 	case reflect.Array:
-		return a.Len() < b.Len()
+		for i := 0; i < a.Len(); i++ {
+			if c := compareValuesSeen(a.Index(i), b.Index(i), seen); c != 0 {
+				return c
+			}
+		}
+		return 0
 	default:
-		return false // not supported yet
+		return 0
+	}
+}
+
+// elem unwraps interface values.
+func elem(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		return v.Elem()
 	}
+	return v
+}
+
+// typeName returns a name identifying t including its package.
+func typeName(t reflect.Type) string {
+	return t.PkgPath() + "." + t.String()
+}
+
+type ordered interface {
+	~int64 | ~uint64 | ~uintptr
+}
+
+func compareOrdered[T ordered](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloats orders NaN values before all other values.
+func compareFloats(a, b float64) int {
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN || bNaN:
+		return compareBools(!aNaN, !bNaN)
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareBools orders false before true.
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
 }
 
 func sortMap(value reflect.Value) *sortedMap {
+	sorted := mapEntries(value)
+	sort.Stable(sorted)
+	return sorted
+}
+
+// mapEntries returns the entries of the given map in the order
+// returned by MapKeys.
+func mapEntries(value reflect.Value) *sortedMap {
 	if value.Type().Kind() != reflect.Map {
-		panic("sortMap is used for a non-Map value")
+		panic("mapEntries is used for a non-Map value")
 	}
 
 	keys := make([]reflect.Value, 0, value.Len())
@@ -84,10 +212,8 @@ func sortMap(value reflect.Value) *sortedMap {
 		values = append(values, value.MapIndex(mapKeys[i]))
 	}
 
-	sorted := &sortedMap{
+	return &sortedMap{
 		keys:   keys,
 		values: values,
 	}
-	sort.Stable(sorted)
-	return sorted
 }
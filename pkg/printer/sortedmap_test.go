@@ -0,0 +1,78 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package printer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	X, Y int
+	Name string
+}
+
+func TestSortMapStructKeys(t *testing.T) {
+	m := map[point]int{
+		{X: 2, Y: 1}:            4,
+		{X: 1, Y: 2}:            2,
+		{X: 1, Y: 1, Name: "b"}: 1,
+		{X: 1, Y: 1, Name: "a"}: 0,
+		{X: 2, Y: 0}:            3,
+	}
+
+	sorted := sortMap(reflect.ValueOf(m))
+	for i := range sorted.values {
+		if got := sorted.values[i].Int(); got != int64(i) {
+			t.Errorf("entry %d is %v, want the value %d", i, sorted.keys[i], i)
+		}
+	}
+}
+
+// ringNode is a node of a circular list.
+type ringNode struct {
+	Value int
+	Next  *ringNode
+}
+
+func TestSortMapCyclicPointerKeys(t *testing.T) {
+	a, b := &ringNode{Value: 1}, &ringNode{Value: 1}
+	a.Next, b.Next = b, a
+
+	c := &ringNode{Value: 0}
+	c.Next = c
+
+	m := map[*ringNode]string{a: "a", b: "b", c: "c"}
+	sorted := sortMap(reflect.ValueOf(m))
+	if got := sorted.values[0].String(); got != "c" {
+		t.Errorf("first entry is %q, want the one with the smallest value", got)
+	}
+
+	out := NewPrinterWithOptions(nil, WithMaxDepth(3)).Format(m)
+	if !strings.Contains(out, "Value") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"os"
+)
+
+// RGB is a 24-bit color.
+type RGB struct {
+	R, G, B uint8
+}
+
+// basicColors are the approximate RGB values of the basic ANSI
+// foreground colors used when down-converting an RGB color.
+var basicColors = []struct {
+	color uint16
+	rgb   RGB
+}{
+	{Black, RGB{0, 0, 0}},
+	{Red, RGB{205, 0, 0}},
+	{Green, RGB{0, 205, 0}},
+	{Yellow, RGB{205, 205, 0}},
+	{Blue, RGB{0, 0, 238}},
+	{Magenta, RGB{205, 0, 205}},
+	{Cyan, RGB{0, 205, 205}},
+	{White, RGB{229, 229, 229}},
+}
+
+// Basic returns the basic foreground color closest to c.
+func (c RGB) Basic() uint16 {
+	sq := func(a, b uint8) int { d := int(a) - int(b); return d * d }
+
+	best, bestDist := Black, -1
+	for _, bc := range basicColors {
+		dist := sq(c.R, bc.rgb.R) + sq(c.G, bc.rgb.G) + sq(c.B, bc.rgb.B)
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = bc.color, dist
+		}
+	}
+	return best
+}
+
+// ParseRGB parses a color in the "#rrggbb" form.
+func ParseRGB(s string) (RGB, error) {
+	var c RGB
+	if len(s) != 7 || s[0] != '#' {
+		return c, fmt.Errorf("invalid rgb color %q", s)
+	}
+	if _, err := fmt.Sscanf(s[1:], "%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return c, fmt.Errorf("invalid rgb color %q", s)
+	}
+	return c, nil
+}
+
+// TrueColorSupported reports whether the terminal announces 24-bit
+// color support through the COLORTERM environment variable.
+func TrueColorSupported() bool {
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return true
+	}
+	return false
+}
+
+// ColorizeTextRGB colorizes text with the given 24-bit foreground
+// color.  The background and bold attributes are taken from color.
+// The RGB color is replaced by the closest basic color unless the
+// terminal supports true color.
+func ColorizeTextRGB(text string, rgb RGB, color uint16) string {
+	if !TrueColorSupported() {
+		return ColorizeText(text, color&^maskForeground|rgb.Basic())
+	}
+
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm%s\033[0m",
+		rgb.R, rgb.G, rgb.B, ColorizeText(text, color&^maskForeground))
+}
@@ -0,0 +1,82 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package printer
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// defaultByteGroupSize is the number of bytes printed per line of a
+// byte slice unless the output is a wider terminal.
+const defaultByteGroupSize = 16
+
+// TerminalWidth returns the number of columns of the terminal
+// standard output is connected to as reported by $COLUMNS or the
+// terminal itself.  It returns 0 if the width is unknown.
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return terminalWidth(os.Stdout)
+}
+
+// writerWidth returns the number of columns of the terminal w is
+// connected to, or 0 if w is not a terminal.
+func writerWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok || !IsTerminal(f) {
+		return 0
+	}
+	if f == os.Stdout {
+		return TerminalWidth()
+	}
+	return terminalWidth(f)
+}
+
+// byteGroupSize returns the number of bytes printed per line of a
+// byte slice.  It grows in multiples of 8 beyond the default of 16
+// bytes if the output width set with SetWidth leaves room for more.
+func (p *Printer) byteGroupSize() int {
+	if p.width <= 0 {
+		return defaultByteGroupSize
+	}
+
+	// Every element takes up to "255, " or "0xff, " characters.
+	elemWidth := 6
+	if p.decimalUint {
+		elemWidth = 5
+	}
+
+	// Leave room for the field name column, the offset column and
+	// the indentation.
+	available := p.width - 2*p.indentWidth*(p.depth+1) - 22
+	if n := available / elemWidth &^ 7; n > defaultByteGroupSize {
+		return n
+	}
+	return defaultByteGroupSize
+}
@@ -0,0 +1,49 @@
+/*
+The MIT License (MIT)
+
+Copyright (c) 2015 Takashi Kokubun
+Copyright (c) 2022 Arthur Skowronek
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package printer
+
+import "testing"
+
+func TestByteGroupSize(t *testing.T) {
+	tests := []struct {
+		width       int
+		decimalUint bool
+		want        int
+	}{
+		{width: 0, want: 16},
+		{width: 40, want: 16},
+		{width: 80, want: 16},
+		{width: 80, decimalUint: true, want: 16},
+		{width: 160, want: 16},
+		{width: 200, want: 24},
+		{width: 240, decimalUint: true, want: 40},
+	}
+	for _, tt := range tests {
+		p := NewPrinterWithOptions(nil, WithWidth(tt.width), WithDecimalUint(tt.decimalUint))
+		if got := p.byteGroupSize(); got != tt.want {
+			t.Errorf("byteGroupSize() with width %d, decimal %v = %d, want %d", tt.width, tt.decimalUint, got, tt.want)
+		}
+	}
+}